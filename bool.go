@@ -0,0 +1,37 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"reflect"
+)
+
+// convertBool turns a Go bool into the 1/0 DM expects for bit columns. Named bool types, *bool and valuers handing
+// out a bool such as sql.NullBool are converted too, every other value is returned as is
+func convertBool(val interface{}) interface{} {
+	switch v := val.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case driver.Valuer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return val
+		}
+		if value, err := v.Value(); err == nil {
+			if b, ok := value.(bool); ok {
+				return convertBool(b)
+			}
+		}
+		return val
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Bool {
+		return convertBool(rv.Bool())
+	}
+	return val
+}
@@ -0,0 +1,41 @@
+package gorm_dm8
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type Flag struct {
+	ID      int
+	Actived bool
+	Checked sql.NullBool
+	Pinned  *bool
+}
+
+func TestBoolVars(t *testing.T) {
+	db := dryRun(t, Config{})
+
+	pinned := true
+	for name, tx := range map[string]*gorm.DB{
+		"create":     db.Create(&Flag{Actived: true, Checked: sql.NullBool{Bool: false, Valid: true}, Pinned: &pinned}),
+		"update":     db.Model(&Flag{ID: 1}).Updates(Flag{Actived: true, Checked: sql.NullBool{Bool: false, Valid: true}, Pinned: &pinned}),
+		"map update": db.Model(&Flag{ID: 1}).Updates(map[string]interface{}{"actived": true, "checked": sql.NullBool{Bool: false, Valid: true}, "pinned": &pinned}),
+		"where":      db.Where("actived = ? AND checked = ? AND pinned = ?", true, sql.NullBool{Bool: false, Valid: true}, &pinned).Find(&[]Flag{}),
+	} {
+		// the bools come first, followed by the primary key or the returning binds
+		if vars := tx.Statement.Vars; len(vars) < 3 || !reflect.DeepEqual(vars[:3], []interface{}{1, 0, 1}) {
+			t.Errorf("%v: expected bools bound as 1, 0, 1, got %v", name, vars)
+		}
+	}
+
+	vars := []interface{}{true}
+	if sql := db.Dialector.Explain("SELECT ?", vars...); sql != "SELECT 1" {
+		t.Errorf("expected bool explained as 1, got %v", sql)
+	}
+	if vars[0] != true {
+		t.Errorf("expected the explained vars to be left alone, got %v", vars)
+	}
+}
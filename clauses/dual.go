@@ -0,0 +1,39 @@
+package clauses
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// Dual selects literal rows from DUAL, it serves as the USING source of a MERGE. Several rows are
+// combined with UNION ALL
+type Dual struct {
+	clause.Values
+}
+
+func (dual Dual) Name() string {
+	return "SELECT"
+}
+
+// Build build dual clause
+func (dual Dual) Build(builder clause.Builder) {
+	for idx, row := range dual.Values.Values {
+		if idx > 0 {
+			builder.WriteString(" UNION ALL SELECT ")
+		}
+		for idx, column := range dual.Columns {
+			if idx > 0 {
+				builder.WriteByte(',')
+			}
+			builder.AddVar(builder, row[idx])
+			builder.WriteString(" AS ")
+			builder.WriteQuoted(column)
+		}
+		builder.WriteString(" FROM DUAL")
+	}
+}
+
+// MergeClause merge dual clauses
+func (dual Dual) MergeClause(clause *clause.Clause) {
+	clause.Name = dual.Name()
+	clause.Expression = dual
+}
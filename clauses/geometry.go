@@ -0,0 +1,41 @@
+package clauses
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// spatial builds a call of a DMGEO function, a string argument names a column, any other argument is bound, e.g.
+// a gorm_dm8.Point which binds itself as DMGEO.ST_GEOMFROMTEXT
+func spatial(function string, suffix string, a, b interface{}) clause.Expr {
+	return clause.Expr{SQL: "DMGEO." + function + "(?, ?)" + suffix, Vars: []interface{}{geometryArg(a), geometryArg(b)}}
+}
+
+func geometryArg(v interface{}) interface{} {
+	if column, ok := v.(string); ok {
+		return clause.Column{Name: column}
+	}
+	return v
+}
+
+// STAsText selects a geometry column as WKT under its own name, the driver returns geometry columns as objects
+// which gorm_dm8.Point, LineString and Polygon can't scan, e.g.
+//
+//	db.Select("ID, ?", STAsText("location")).Find(&places)
+func STAsText(column string) clause.Expr {
+	return clause.Expr{SQL: "DMGEO.ST_ASTEXT(?) AS ?", Vars: []interface{}{clause.Column{Name: column}, clause.Column{Name: column}}}
+}
+
+// STDistance is the distance between two geometries, e.g. Where("? < ?", STDistance("location", point), 100)
+func STDistance(a, b interface{}) clause.Expr {
+	return spatial("ST_DISTANCE", "", a, b)
+}
+
+// STContains is the condition that geometry a contains geometry b
+func STContains(a, b interface{}) clause.Expr {
+	return spatial("ST_CONTAINS", " = 1", a, b)
+}
+
+// STIntersects is the condition that geometries a and b intersect
+func STIntersects(a, b interface{}) clause.Expr {
+	return spatial("ST_INTERSECTS", " = 1", a, b)
+}
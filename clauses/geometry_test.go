@@ -0,0 +1,49 @@
+package clauses_test
+
+import (
+	"strings"
+	"testing"
+
+	gorm_dm8 "github.com/ximenhaoziye/gorm-dm8"
+	"github.com/ximenhaoziye/gorm-dm8/clauses"
+	"gorm.io/gorm"
+)
+
+type Place struct {
+	ID       int
+	Location gorm_dm8.Point
+	Route    *gorm_dm8.LineString
+	Area     gorm_dm8.Polygon
+}
+
+func TestSpatialFunctions(t *testing.T) {
+	db := dryRun(t)
+	point := gorm_dm8.Point{X: 1, Y: 2, SRID: 4326}
+
+	for _, c := range []struct {
+		query    *gorm.DB
+		expected string
+	}{
+		{db.Where(clauses.STContains("area", point)), `DMGEO.ST_CONTAINS("AREA", DMGEO.ST_GEOMFROMTEXT(?, ?)) = 1`},
+		{db.Where(clauses.STIntersects("area", "location")), `DMGEO.ST_INTERSECTS("AREA", "LOCATION") = 1`},
+		{db.Where("? < ?", clauses.STDistance("location", point), 100), `DMGEO.ST_DISTANCE("LOCATION", DMGEO.ST_GEOMFROMTEXT(?, ?)) < ?`},
+	} {
+		expected := ` SELECT *  FROM "PLACES"  WHERE ` + c.expected
+		if sql := c.query.Find(&[]Place{}).Statement.SQL.String(); sql != expected {
+			t.Errorf("expected %v, got %v", expected, sql)
+		}
+	}
+
+	stmt := db.Session(&gorm.Session{SkipDefaultTransaction: true}).Create(&Place{Location: point}).Statement
+	if expected := `INSERT INTO "PLACES" ("LOCATION","ROUTE","AREA") VALUES (DMGEO.ST_GEOMFROMTEXT(?, ?),?,DMGEO.ST_GEOMFROMTEXT(?, ?)) RETURNING "ID" INTO ?`; stmt.SQL.String() != expected {
+		t.Errorf("expected %v, got %v", expected, stmt.SQL.String())
+	}
+	if len(stmt.Vars) < 5 || stmt.Vars[0] != "POINT (1 2)" || stmt.Vars[1] != 4326 || stmt.Vars[2] != nil || stmt.Vars[3] != "POLYGON EMPTY" {
+		t.Errorf("expected point bound as WKT and SRID, nil route as NULL and the empty polygon as POLYGON EMPTY, got %v", stmt.Vars)
+	}
+
+	sql := db.Select("ID, ?", clauses.STAsText("location")).Find(&[]Place{}).Statement.SQL.String()
+	if expected := `SELECT ID, DMGEO.ST_ASTEXT("LOCATION") AS "LOCATION" FROM "PLACES"`; strings.Join(strings.Fields(sql), " ") != expected {
+		t.Errorf("expected %v, got %v", expected, sql)
+	}
+}
@@ -0,0 +1,62 @@
+package clauses
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// JSONQueryExpression renders the DM JSON functions on a JSON column, e.g.
+// JSONQuery("attrs").Extract("$.a").Equals(1) builds JSON_VALUE("ATTRS", '$.a') = ?
+type JSONQueryExpression struct {
+	column string
+	path   string
+	scalar bool
+	equals bool
+	value  interface{}
+}
+
+// JSONQuery starts an expression on the JSON column
+func JSONQuery(column string) *JSONQueryExpression {
+	return &JSONQueryExpression{column: column, path: "$"}
+}
+
+// Extract selects the part of the document at the SQL/JSON path, on its own it builds JSON_QUERY
+func (q *JSONQueryExpression) Extract(path string) *JSONQueryExpression {
+	q.path = path
+	return q
+}
+
+// Value builds JSON_VALUE, the scalar at the path
+func (q *JSONQueryExpression) Value() *JSONQueryExpression {
+	q.scalar = true
+	return q
+}
+
+// Equals compares the scalar at the path with value
+func (q *JSONQueryExpression) Equals(value interface{}) *JSONQueryExpression {
+	q.scalar, q.equals, q.value = true, true, value
+	return q
+}
+
+func (q *JSONQueryExpression) Build(builder clause.Builder) {
+	if q.scalar {
+		builder.WriteString("JSON_VALUE(")
+	} else {
+		builder.WriteString("JSON_QUERY(")
+	}
+	builder.WriteQuoted(clause.Column{Name: q.column})
+	builder.WriteString(", '")
+	// DM only takes the path as literal
+	builder.WriteString(strings.ReplaceAll(q.path, "'", "''"))
+	builder.WriteString("')")
+
+	if q.equals {
+		if q.value == nil {
+			builder.WriteString(" IS NULL")
+			return
+		}
+		builder.WriteString(" = ")
+		builder.AddVar(builder, q.value)
+	}
+}
@@ -0,0 +1,33 @@
+package clauses_test
+
+import (
+	"testing"
+
+	"github.com/ximenhaoziye/gorm-dm8/clauses"
+	"gorm.io/gorm"
+)
+
+type Product struct {
+	ID    int
+	Attrs string
+}
+
+func TestJSONQuery(t *testing.T) {
+	db := dryRun(t)
+
+	for _, c := range []struct {
+		query    *gorm.DB
+		expected string
+	}{
+		{db.Where(clauses.JSONQuery("attrs").Extract("$.a").Equals(1)), `JSON_VALUE("ATTRS", '$.a') = ?`},
+		{db.Where(clauses.JSONQuery("attrs").Extract("$.b[0]").Equals(nil)), `JSON_VALUE("ATTRS", '$.b[0]') IS NULL`},
+		{db.Where(clauses.JSONQuery("attrs").Extract(`$."it's"`).Equals("x")), `JSON_VALUE("ATTRS", '$."it''s"') = ?`},
+		{db.Where("? = 'red'", clauses.JSONQuery("attrs").Extract("$.color").Value()), `JSON_VALUE("ATTRS", '$.color') = 'red'`},
+		{db.Where("? IS NOT NULL", clauses.JSONQuery("attrs").Extract("$.tags")), `JSON_QUERY("ATTRS", '$.tags') IS NOT NULL`},
+	} {
+		expected := ` SELECT *  FROM "PRODUCTS"  WHERE ` + c.expected
+		if sql := c.query.Find(&[]Product{}).Statement.SQL.String(); sql != expected {
+			t.Errorf("expected %v, got %v", expected, sql)
+		}
+	}
+}
@@ -7,6 +7,10 @@ import (
 type Merge struct {
 	Table clause.Table
 	Using []clause.Interface
+	// Source is merged from in place of Using, a table name, clause.Table, a subquery (*gorm.DB) or an expression
+	Source interface{}
+	// Alias names the USING source in the ON condition and the WHEN branches, MergeDefaultExcludeName by default
+	Alias string
 	On    []clause.Expression
 }
 
@@ -18,24 +22,51 @@ func MergeDefaultExcludeName() string {
 	return "exclude"
 }
 
+// SourceAlias returns the name of the USING source
+func (merge Merge) SourceAlias() string {
+	if merge.Alias != "" {
+		return merge.Alias
+	}
+	return MergeDefaultExcludeName()
+}
+
 // Build build from clause
 func (merge Merge) Build(builder clause.Builder) {
-	clause.Insert{}.Build(builder)
-	builder.WriteString(" USING (")
-	for idx, iface := range merge.Using {
-		if idx > 0 {
+	builder.WriteString("INTO ")
+	if merge.Table.Name == "" {
+		builder.WriteQuoted(clause.Table{Name: clause.CurrentTable, Alias: merge.Table.Alias})
+	} else {
+		builder.WriteQuoted(merge.Table)
+	}
+
+	builder.WriteString(" USING ")
+	switch source := merge.Source.(type) {
+	case nil:
+		builder.WriteByte('(')
+		for idx, iface := range merge.Using {
+			if idx > 0 {
+				builder.WriteByte(' ')
+			}
+			builder.WriteString(iface.Name())
 			builder.WriteByte(' ')
+			iface.Build(builder)
 		}
-		builder.WriteString(iface.Name())
-		builder.WriteByte(' ')
-		iface.Build(builder)
+		builder.WriteByte(')')
+	case string:
+		builder.WriteQuoted(clause.Table{Name: source})
+	case clause.Table:
+		builder.WriteQuoted(clause.Table{Name: source.Name, Raw: source.Raw})
+	default:
+		builder.WriteByte('(')
+		builder.AddVar(builder, source)
+		builder.WriteByte(')')
 	}
-	builder.WriteString(") ")
-	builder.WriteString(MergeDefaultExcludeName())
+	builder.WriteByte(' ')
+	builder.WriteQuoted(merge.SourceAlias())
 	builder.WriteString(" ON (")
 	for idx, on := range merge.On {
 		if idx > 0 {
-			builder.WriteString(", ")
+			builder.WriteString(" AND ")
 		}
 		on.Build(builder)
 	}
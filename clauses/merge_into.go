@@ -0,0 +1,77 @@
+package clauses
+
+import (
+	"gorm.io/gorm/clause"
+)
+
+// MergeStatement is a complete MERGE with at most one WHEN MATCHED and one WHEN NOT MATCHED branch, DM doesn't
+// support repeating them, built with MergeInto and executed with
+//
+//	db.Clauses(clauses.MergeInto("LIVE").Using("STAGING", "s").On(...).WhenMatchedUpdate(...)).Exec("")
+type MergeStatement struct {
+	Merge
+	Matched    WhenMatched
+	NotMatched WhenNotMatched
+}
+
+// MergeInto starts a MERGE into table
+func MergeInto(table string) MergeStatement {
+	return MergeStatement{Merge: Merge{Table: clause.Table{Name: table}}}
+}
+
+// Using sets the source merged from, a table name, clause.Table, a subquery (*gorm.DB) or an expression
+func (m MergeStatement) Using(source interface{}, alias string) MergeStatement {
+	m.Source = source
+	m.Alias = alias
+	return m
+}
+
+// On adds conditions matching the source rows to the target rows
+func (m MergeStatement) On(exprs ...clause.Expression) MergeStatement {
+	m.Merge.On = append(m.Merge.On[:len(m.Merge.On):len(m.Merge.On)], exprs...)
+	return m
+}
+
+// WhenMatchedUpdate updates the matched rows meeting conds: THEN UPDATE SET ... WHERE ...
+func (m MergeStatement) WhenMatchedUpdate(set clause.Set, conds ...clause.Expression) MergeStatement {
+	m.Matched.Set = set
+	m.Matched.Where = clause.Where{Exprs: conds}
+	return m
+}
+
+// WhenMatchedDelete deletes the updated rows meeting conds: DELETE WHERE ..., the conditions see the updated
+// values and DM requires WhenMatchedUpdate too
+func (m MergeStatement) WhenMatchedDelete(conds ...clause.Expression) MergeStatement {
+	m.Matched.Delete = clause.Where{Exprs: conds}
+	return m
+}
+
+// WhenNotMatchedInsert inserts the source rows meeting conds that match no target row: THEN INSERT ... WHERE ...
+func (m MergeStatement) WhenNotMatchedInsert(values clause.Values, conds ...clause.Expression) MergeStatement {
+	m.NotMatched.Values = values
+	m.NotMatched.Where = clause.Where{Exprs: conds}
+	return m
+}
+
+// Build build merge statement
+func (m MergeStatement) Build(builder clause.Builder) {
+	m.Merge.Build(builder)
+	if len(m.Matched.Set) > 0 || len(m.Matched.Delete.Exprs) > 0 {
+		builder.WriteByte(' ')
+		builder.WriteString(m.Matched.Name())
+		builder.WriteByte(' ')
+		m.Matched.Build(builder)
+	}
+	if len(m.NotMatched.Columns) > 0 {
+		builder.WriteByte(' ')
+		builder.WriteString(m.NotMatched.Name())
+		builder.WriteByte(' ')
+		m.NotMatched.Build(builder)
+	}
+}
+
+// MergeClause merge statement clauses
+func (m MergeStatement) MergeClause(clause *clause.Clause) {
+	clause.Name = m.Name()
+	clause.Expression = m
+}
@@ -0,0 +1,80 @@
+package clauses_test
+
+import (
+	"database/sql"
+	"testing"
+
+	gorm_dm8 "github.com/ximenhaoziye/gorm-dm8"
+	"github.com/ximenhaoziye/gorm-dm8/clauses"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func dryRun(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(gorm_dm8.New(gorm_dm8.Config{Conn: &sql.DB{}}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open dry run db: %v", err)
+	}
+	return db
+}
+
+func TestMergeStatement(t *testing.T) {
+	db := dryRun(t)
+
+	merge := clauses.MergeInto("LIVE").
+		Using(db.Table("STAGING").Where("BATCH = ?", 7), "s").
+		On(
+			clause.Eq{Column: clause.Column{Table: "LIVE", Name: "ID"}, Value: clause.Column{Table: "s", Name: "ID"}},
+			clause.Eq{Column: clause.Column{Table: "LIVE", Name: "KIND"}, Value: clause.Column{Table: "s", Name: "KIND"}},
+		).
+		WhenMatchedUpdate(
+			clause.Set{{Column: clause.Column{Name: "NAME"}, Value: clause.Column{Table: "s", Name: "NAME"}}},
+			clause.Neq{Column: clause.Column{Table: "LIVE", Name: "NAME"}, Value: clause.Column{Table: "s", Name: "NAME"}},
+		).
+		WhenMatchedDelete(clause.Eq{Column: clause.Column{Table: "s", Name: "DELETED"}, Value: 1}).
+		WhenNotMatchedInsert(clause.Values{
+			Columns: []clause.Column{{Name: "ID"}, {Name: "KIND"}, {Name: "NAME"}},
+			Values:  [][]interface{}{{clause.Column{Table: "s", Name: "ID"}, clause.Column{Table: "s", Name: "KIND"}, clause.Column{Table: "s", Name: "NAME"}}},
+		}, clause.Eq{Column: clause.Column{Table: "s", Name: "DELETED"}, Value: 0})
+
+	stmt := db.Clauses(merge).Exec("").Statement
+	expected := `MERGE INTO "LIVE" USING ( SELECT *  FROM "STAGING"  WHERE BATCH = ?) "S" ON ("LIVE"."ID" = "S"."ID" AND "LIVE"."KIND" = "S"."KIND") ` +
+		`WHEN MATCHED THEN UPDATE SET "NAME"="S"."NAME" WHERE "LIVE"."NAME" <> "S"."NAME" DELETE WHERE "S"."DELETED" = ? ` +
+		`WHEN NOT MATCHED THEN INSERT ("ID","KIND","NAME") VALUES ("S"."ID","S"."KIND","S"."NAME") WHERE "S"."DELETED" = ?`
+	if sql := stmt.SQL.String(); sql != expected {
+		t.Errorf("expected %v, got %v", expected, sql)
+	}
+	if len(stmt.Vars) != 3 || stmt.Vars[0] != 7 || stmt.Vars[1] != 1 || stmt.Vars[2] != 0 {
+		t.Errorf("unexpected vars %v", stmt.Vars)
+	}
+}
+
+func TestMergeFromTable(t *testing.T) {
+	db := dryRun(t)
+
+	merge := clauses.MergeInto("LIVE").
+		Using("STAGING", "s").
+		On(clause.Eq{Column: clause.Column{Table: "LIVE", Name: "ID"}, Value: clause.Column{Table: "s", Name: "ID"}}).
+		WhenMatchedUpdate(clause.Set{{Column: clause.Column{Name: "NAME"}, Value: clause.Column{Table: "s", Name: "NAME"}}})
+
+	expected := `MERGE INTO "LIVE" USING "STAGING" "S" ON ("LIVE"."ID" = "S"."ID") WHEN MATCHED THEN UPDATE SET "NAME"="S"."NAME"`
+	if sql := db.Clauses(merge).Exec("").Statement.SQL.String(); sql != expected {
+		t.Errorf("expected %v, got %v", expected, sql)
+	}
+}
+
+func TestMergeDeleteWithoutUpdate(t *testing.T) {
+	db := dryRun(t)
+
+	merge := clauses.MergeInto("LIVE").
+		Using("STAGING", "s").
+		On(clause.Eq{Column: clause.Column{Table: "LIVE", Name: "ID"}, Value: clause.Column{Table: "s", Name: "ID"}}).
+		WhenMatchedDelete(clause.Eq{Column: clause.Column{Table: "s", Name: "DELETED"}, Value: 1})
+
+	if err := db.Clauses(merge).Exec("").Error; err == nil {
+		t.Errorf("expected deleting matched rows without updating them to fail")
+	}
+}
@@ -1,9 +1,13 @@
 package clauses
 
 import (
+	"errors"
+
 	"gorm.io/gorm/clause"
 )
 
+// WhenMatched updates the matched rows: THEN UPDATE SET ... [WHERE ...] [DELETE WHERE ...], the DELETE WHERE
+// conditions are checked against the updated rows, so DM can only delete matched rows it updates
 type WhenMatched struct {
 	clause.Set
 	Where, Delete clause.Where
@@ -14,26 +18,38 @@ func (w WhenMatched) Name() string {
 }
 
 func (w WhenMatched) Build(builder clause.Builder) {
-	if len(w.Set) > 0 {
-		builder.WriteString(" THEN")
-		builder.WriteString(" UPDATE ")
-		builder.WriteString(w.Name())
-		builder.WriteByte(' ')
-		w.Build(builder)
-
-		buildWhere := func(where clause.Where) {
-			builder.WriteString(where.Name())
-			builder.WriteByte(' ')
-			where.Build(builder)
+	if len(w.Set) == 0 {
+		if len(w.Delete.Exprs) > 0 {
+			builder.AddError(errors.New("cannot delete matched rows without updating them due to DM SQL language restriction"))
 		}
+		return
+	}
 
-		if len(w.Where.Exprs) > 0 {
-			buildWhere(w.Where)
-		}
+	builder.WriteString("THEN")
+	builder.WriteString(" UPDATE ")
+	builder.WriteString(w.Set.Name())
+	builder.WriteByte(' ')
+	w.Set.Build(builder)
 
-		if len(w.Delete.Exprs) > 0 {
-			builder.WriteString(" DELETE ")
-			buildWhere(w.Delete)
-		}
+	buildWhere := func(where clause.Where) {
+		builder.WriteString(where.Name())
+		builder.WriteByte(' ')
+		where.Build(builder)
+	}
+
+	if len(w.Where.Exprs) > 0 {
+		builder.WriteByte(' ')
+		buildWhere(w.Where)
 	}
+
+	if len(w.Delete.Exprs) > 0 {
+		builder.WriteString(" DELETE ")
+		buildWhere(w.Delete)
+	}
+}
+
+// MergeClause merge when matched clauses
+func (w WhenMatched) MergeClause(clause *clause.Clause) {
+	clause.Name = w.Name()
+	clause.Expression = w
 }
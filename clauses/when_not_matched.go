@@ -1,6 +1,8 @@
 package clauses
 
 import (
+	"errors"
+
 	"gorm.io/gorm/clause"
 )
 
@@ -16,17 +18,26 @@ func (w WhenNotMatched) Name() string {
 func (w WhenNotMatched) Build(builder clause.Builder) {
 	if len(w.Columns) > 0 {
 		if len(w.Values.Values) != 1 {
-			panic("cannot insert more than one rows due to DM SQL language restriction")
+			// several rows have to be merged from the USING source, e.g. with Dual
+			builder.AddError(errors.New("cannot insert more than one rows due to DM SQL language restriction"))
+			return
 		}
 
-		builder.WriteString(" THEN")
+		builder.WriteString("THEN")
 		builder.WriteString(" INSERT ")
-		w.Build(builder)
+		w.Values.Build(builder)
 
 		if len(w.Where.Exprs) > 0 {
+			builder.WriteByte(' ')
 			builder.WriteString(w.Where.Name())
 			builder.WriteByte(' ')
 			w.Where.Build(builder)
 		}
 	}
 }
+
+// MergeClause merge when not matched clauses
+func (w WhenNotMatched) MergeClause(clause *clause.Clause) {
+	clause.Name = w.Name()
+	clause.Expression = w
+}
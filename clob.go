@@ -0,0 +1,368 @@
+package gorm_dm8
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// Clob is a string stored as CLOB, it scans the *dm.DmClob returned by the driver on connections the dialector didn't
+// open, a Config.Conn without clobAsString=true in its DSN
+type Clob string
+
+// clobReader reads a *dm.DmClob, positions start at 1
+type clobReader interface {
+	GetLength() (int64, error)
+	ReadString(pos int, length int) (string, error)
+}
+
+func (c *Clob) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = ""
+	case string:
+		*c = Clob(v)
+	case []byte:
+		*c = Clob(v)
+	case clobReader:
+		length, err := v.GetLength()
+		if err != nil {
+			return err
+		}
+		if length == 0 {
+			*c = ""
+			return nil
+		}
+		s, err := v.ReadString(1, int(length))
+		if err != nil {
+			return err
+		}
+		*c = Clob(s)
+	default:
+		return fmt.Errorf("failed to scan %T into Clob", value)
+	}
+	return nil
+}
+
+func (c Clob) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+func (Clob) GormDataType() string {
+	return "clob"
+}
+
+type locatorsKey struct{}
+
+// withLocators makes the queries run with ctx return the CLOB locators of the driver, OpenLobReader and
+// OpenLobWriter stream through them
+func withLocators(ctx context.Context) context.Context {
+	return context.WithValue(ctx, locatorsKey{}, true)
+}
+
+// clobConnector reads the *dm.DmClob locators returned by the driver into strings, so CLOB columns scan into string
+// fields without clobAsString=true in the DSN. Queries run with a context from withLocators keep the locators
+type clobConnector struct {
+	driver.Connector
+}
+
+func (c clobConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return clobConn{conn}, nil
+}
+
+// clobConn wraps the rows of the queries of a connection, the optional interfaces of the connection are passed on
+type clobConn struct {
+	driver.Conn
+}
+
+func (c clobConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c clobConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return clobStmt{Stmt: stmt, conn: c.Conn}, nil
+}
+
+func (c clobConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		return nil, errors.New("driver does not support non-default isolation level nor read-only transactions")
+	}
+	return c.Conn.Begin()
+}
+
+func (c clobConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c clobConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		rows, err := queryer.QueryContext(ctx, query, args)
+		return readClobs(ctx, rows, err)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c clobConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (c clobConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c clobConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c clobConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// clobStmt wraps the rows of the queries of a prepared statement
+type clobStmt struct {
+	driver.Stmt
+	conn driver.Conn
+}
+
+func (s clobStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	return s.Stmt.Exec(driverValues(args))
+}
+
+func (s clobStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.Stmt.Query(args)
+	return readClobs(context.Background(), rows, err)
+}
+
+func (s clobStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(driverValues(args))
+	}
+	return readClobs(ctx, rows, err)
+}
+
+// CheckNamedValue checks with the statement, then with the connection, as database/sql does without the wrapper
+func (s clobStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	if checker, ok := s.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (s clobStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if converter, ok := s.Stmt.(driver.ColumnConverter); ok {
+		return converter.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+func driverValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for idx, arg := range args {
+		values[idx] = arg.Value
+	}
+	return values
+}
+
+// readClobs wraps rows to read their CLOB locators into strings, unless ctx asks for the locators
+func readClobs(ctx context.Context, rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+	if locators, _ := ctx.Value(locatorsKey{}).(bool); locators {
+		return rows, nil
+	}
+	return clobRows{rows}, nil
+}
+
+var clobReaderType = reflect.TypeOf((*clobReader)(nil)).Elem()
+
+// clobRows reads the CLOB locators of the rows into strings, the column types of the rows are passed on
+type clobRows struct {
+	driver.Rows
+}
+
+func (r clobRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for idx, value := range dest {
+		if locator, ok := value.(clobReader); ok {
+			var c Clob
+			if err := c.Scan(locator); err != nil {
+				return err
+			}
+			dest[idx] = string(c)
+		}
+	}
+	return nil
+}
+
+func (r clobRows) HasNextResultSet() bool {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.HasNextResultSet()
+	}
+	return false
+}
+
+func (r clobRows) NextResultSet() error {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.NextResultSet()
+	}
+	return io.EOF
+}
+
+// ColumnTypeScanType is string for the CLOB columns read into strings
+func (r clobRows) ColumnTypeScanType(idx int) reflect.Type {
+	rows, ok := r.Rows.(driver.RowsColumnTypeScanType)
+	if !ok {
+		return reflect.TypeOf(new(interface{})).Elem()
+	}
+	scanType := rows.ColumnTypeScanType(idx)
+	if scanType != nil && (scanType.Implements(clobReaderType) || reflect.PtrTo(scanType).Implements(clobReaderType)) {
+		return reflect.TypeOf("")
+	}
+	return scanType
+}
+
+func (r clobRows) ColumnTypeDatabaseTypeName(idx int) string {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return rows.ColumnTypeDatabaseTypeName(idx)
+	}
+	return ""
+}
+
+func (r clobRows) ColumnTypeLength(idx int) (int64, bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return rows.ColumnTypeLength(idx)
+	}
+	return 0, false
+}
+
+func (r clobRows) ColumnTypeNullable(idx int) (nullable, ok bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return rows.ColumnTypeNullable(idx)
+	}
+	return false, false
+}
+
+func (r clobRows) ColumnTypePrecisionScale(idx int) (precision, scale int64, ok bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return rows.ColumnTypePrecisionScale(idx)
+	}
+	return 0, 0, false
+}
+
+// isClob reports whether the field is stored as CLOB, which can't be compared with =, the type given by the field
+// type such as JSON comes first
+func (d Dialector) isClob(stmt *gorm.Statement, field *schema.Field) bool {
+	dataType := d.DataTypeOf(field)
+	if dataTyper, ok := reflect.New(field.IndirectFieldType).Interface().(migrator.GormDataTypeInterface); ok {
+		if dbDataType := dataTyper.GormDBDataType(stmt.DB, field); dbDataType != "" {
+			dataType = dbDataType
+		}
+	}
+	dataType = strings.ToLower(dataType)
+	for _, clobType := range []string{"clob", "text", "longvarchar"} {
+		if strings.HasPrefix(dataType, clobType) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareClobs rewrites equality conditions on CLOB columns of the statement's model to DBMS_LOB.COMPARE
+func (d Dialector) compareClobs(stmt *gorm.Statement, exprs []clause.Expression) []clause.Expression {
+	if stmt.Schema == nil {
+		return exprs
+	}
+
+	clobColumn := func(column interface{}) (clause.Column, bool) {
+		var c clause.Column
+		switch v := column.(type) {
+		case clause.Column:
+			c = v
+		case string:
+			c = clause.Column{Name: v}
+		default:
+			return c, false
+		}
+		if c.Table != "" && c.Table != clause.CurrentTable && c.Table != stmt.Table {
+			return c, false
+		}
+		field := stmt.Schema.LookUpField(c.Name)
+		if field == nil {
+			field = stmt.Schema.LookUpField(d.IdentifierCase.Convert(c.Name))
+		}
+		return c, field != nil && d.isClob(stmt, field)
+	}
+
+	rewritten := make([]clause.Expression, len(exprs))
+	for idx, expr := range exprs {
+		switch v := expr.(type) {
+		case clause.Eq:
+			if column, ok := clobColumn(v.Column); ok && v.Value != nil {
+				expr = clause.Expr{SQL: "DBMS_LOB.COMPARE(?, ?) = 0", Vars: []interface{}{column, v.Value}}
+			}
+		case clause.Neq:
+			if column, ok := clobColumn(v.Column); ok && v.Value != nil {
+				expr = clause.Expr{SQL: "DBMS_LOB.COMPARE(?, ?) <> 0", Vars: []interface{}{column, v.Value}}
+			}
+		case clause.AndConditions:
+			expr = clause.AndConditions{Exprs: d.compareClobs(stmt, v.Exprs)}
+		case clause.OrConditions:
+			expr = clause.OrConditions{Exprs: d.compareClobs(stmt, v.Exprs)}
+		case clause.NotConditions:
+			expr = clause.NotConditions{Exprs: d.compareClobs(stmt, v.Exprs)}
+		}
+		rewritten[idx] = expr
+	}
+	return rewritten
+}
@@ -0,0 +1,94 @@
+package gorm_dm8
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Post struct {
+	ID      int
+	Title   string `gorm:"size:100"`
+	Content string `gorm:"type:clob"`
+	Summary string
+	Attrs   JSON
+}
+
+func TestCompareClobs(t *testing.T) {
+	db := dryRun(t, Config{})
+
+	for name, c := range map[string]struct {
+		query    func(tx *gorm.DB) *gorm.DB
+		expected string
+	}{
+		"struct": {
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(&Post{Title: "a", Content: "b"}).Find(&[]Post{}) },
+			`SELECT * FROM "POSTS" WHERE "POSTS"."TITLE" = 'a' AND DBMS_LOB.COMPARE("POSTS"."CONTENT", 'b') = 0`,
+		},
+		"map": {
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(map[string]interface{}{"content": "b"}).Find(&[]Post{}) },
+			`SELECT * FROM "POSTS" WHERE DBMS_LOB.COMPARE("CONTENT", 'b') = 0`,
+		},
+		"not": {
+			func(tx *gorm.DB) *gorm.DB { return tx.Not(map[string]interface{}{"CONTENT": "b"}).Find(&[]Post{}) },
+			`SELECT * FROM "POSTS" WHERE NOT DBMS_LOB.COMPARE("CONTENT", 'b') = 0`,
+		},
+		"neq": {
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(clause.Neq{Column: "CONTENT", Value: "b"}).Find(&[]Post{}) },
+			`SELECT * FROM "POSTS" WHERE DBMS_LOB.COMPARE("CONTENT", 'b') <> 0`,
+		},
+		"json": {
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(&Post{Attrs: JSON(`{"a":1}`)}).Find(&[]Post{}) },
+			`SELECT * FROM "POSTS" WHERE DBMS_LOB.COMPARE("POSTS"."ATTRS", '{"a":1}') = 0`,
+		},
+		"unsized": {
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(&Post{Summary: "c"}).Find(&[]Post{}) },
+			`SELECT * FROM "POSTS" WHERE DBMS_LOB.COMPARE("POSTS"."SUMMARY", 'c') = 0`,
+		},
+		"varchar": {
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(&Post{Title: "c"}).Find(&[]Post{}) },
+			`SELECT * FROM "POSTS" WHERE "POSTS"."TITLE" = 'c'`,
+		},
+	} {
+		sql := strings.Join(strings.Fields(db.ToSQL(c.query)), " ")
+		if sql != c.expected {
+			t.Errorf("expected %v for %v, got %v", c.expected, name, sql)
+		}
+	}
+}
+
+func TestClobConnector(t *testing.T) {
+	text := strings.Repeat("达梦", 5000)
+	fake := &fakeDB{query: func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		return []string{"ID", "SUMMARY"}, [][]driver.Value{{int64(1), &fakeClob{runes: []rune(text)}}}, nil
+	}}
+	db := fake.openDriver(t, Config{})
+	if testDriver.dsn != "dm://SYSDBA:SYSDBA@localhost:5236" {
+		t.Errorf("expected the DSN to be left alone, got %v", testDriver.dsn)
+	}
+
+	var posts []Post
+	if err := db.Find(&posts).Error; err != nil || len(posts) != 1 || posts[0].Summary != text {
+		t.Errorf("expected the CLOB to be read into a string, got %v, %v", len(posts), err)
+	}
+
+	var rows []map[string]interface{}
+	if err := db.Model(&Post{}).Find(&rows).Error; err != nil || len(rows) != 1 || rows[0]["SUMMARY"] != text {
+		t.Errorf("expected the CLOB to be read into a map, got %v", err)
+	}
+
+	var (
+		id    int
+		value interface{}
+	)
+	if err := db.WithContext(withLocators(context.Background())).Raw("SELECT ID, SUMMARY FROM POSTS").Row().Scan(&id, &value); err != nil {
+		t.Fatalf("failed to select locator, got error %v", err)
+	}
+	if _, ok := value.(*fakeClob); !ok {
+		t.Errorf("expected the locator to be kept, got %T", value)
+	}
+}
@@ -1,9 +1,8 @@
 package gorm_dm8
 
 import (
-	"bytes"
-	"context"
 	"database/sql"
+	"fmt"
 	"github.com/thoas/go-funk"
 	"reflect"
 
@@ -11,8 +10,6 @@ import (
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
 	gormSchema "gorm.io/gorm/schema"
-
-	"github.com/ximenhaoziye/gorm-dm8/clauses"
 )
 
 func Create(db *gorm.DB) {
@@ -20,7 +17,7 @@ func Create(db *gorm.DB) {
 	schema := stmt.Schema
 	boundVars := make(map[string]int)
 
-	if stmt == nil || schema == nil {
+	if db.Error != nil || stmt == nil || schema == nil {
 		return
 	}
 
@@ -34,45 +31,59 @@ func Create(db *gorm.DB) {
 
 	if stmt.SQL.String() == "" {
 		values := callbacks.ConvertToCreateValues(stmt)
+		if db.Error != nil {
+			return
+		}
 		onConflict, hasConflict := stmt.Clauses["ON CONFLICT"].Expression.(clause.OnConflict)
-		// are all columns in value the primary fields in schema only?
-		if hasConflict && funk.Contains(
-			funk.Map(values.Columns, func(c clause.Column) string { return c.Name }),
-			funk.Map(schema.PrimaryFields, func(field *gormSchema.Field) string { return field.DBName }),
+		if hasConflict && len(onConflict.Columns) == 0 {
+			// use primary fields as default OnConflict columns
+			for _, field := range schema.PrimaryFields {
+				onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
+			}
+			stmt.AddClause(onConflict)
+		}
+		columnName := func(c clause.Column) string {
+			if field := schema.LookUpField(c.Name); field != nil {
+				return field.DBName
+			}
+			return db.NamingStrategy.ColumnName("", c.Name)
+		}
+		// a conflict can only be detected when all of its columns are inserted, the upsert is rewritten to a MERGE
+		if hasConflict && len(onConflict.Columns) > 0 && funk.Subset(
+			funk.Map(onConflict.Columns, columnName),
+			funk.Map(values.Columns, columnName),
 		) {
-			stmt.AddClauseIfNotExists(clauses.Merge{
-				Using: []clause.Interface{
-					clause.Select{
-						Columns: funk.Map(values.Columns, func(column clause.Column) clause.Column {
-							// HACK: I can not come up with a better alternative for now
-							// I want to add a value to the list of variable and then capture the bind variable position as well
-							buf := bytes.NewBufferString("")
-							stmt.Vars = append(stmt.Vars, values.Values[0][funk.IndexOf(values.Columns, column)])
-							stmt.BindVarTo(buf, stmt, nil)
-
-							column.Alias = column.Name
-							// then the captured bind var will be the name
-							column.Name = buf.String()
-							return column
-						}).([]clause.Column),
-					},
-					clause.From{
-						Tables: []clause.Table{{Name: db.Dialector.(Dialector).DummyTableName()}},
-					},
-				},
-				On: funk.Map(schema.PrimaryFields, func(field *gormSchema.Field) clause.Expression {
-					return clause.Eq{
-						Column: clause.Column{Table: stmt.Table, Name: field.DBName},
-						Value:  clause.Column{Table: clauses.MergeDefaultExcludeName(), Name: field.DBName},
-					}
-				}).([]clause.Expression),
-			})
-			stmt.AddClauseIfNotExists(clauses.WhenMatched{Set: onConflict.DoUpdates})
-			stmt.AddClauseIfNotExists(clauses.WhenNotMatched{Values: values})
+			// a single MERGE upserts every row of the batch
+			stmt.AddClause(values)
+			stmt.Build("ON CONFLICT")
 
-			stmt.Build("MERGE", "WHEN MATCHED", "WHEN NOT MATCHED")
+			if !db.DryRun && db.Error == nil {
+				if result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...); db.AddError(err) == nil {
+					db.RowsAffected, _ = result.RowsAffected()
+				}
+			}
+			return
+		} else if hasConflict {
+			// the rows only miss conflict columns DM generates a new key for, they can't conflict and are inserted
+			for _, column := range onConflict.Columns {
+				if funk.Contains(funk.Map(values.Columns, columnName), columnName(column)) {
+					continue
+				}
+				if field := schema.LookUpField(columnName(column)); field == nil || !field.PrimaryKey ||
+					!funk.Contains(schema.FieldsWithDefaultDBValue, field) {
+					db.AddError(fmt.Errorf("ON CONFLICT column %s is not inserted, DM only detects conflicts on inserted columns", columnName(column)))
+					return
+				}
+			}
+		}
+
+		if len(values.Values) > 1 && !hasDefaultValues {
+			// every row goes in with one multi-row INSERT, rows with values generated by DM take the RETURNING path
+			// below as only a single row INSERT hands them back
+			createBatch(db, values)
+			return
 		} else {
-			stmt.AddClauseIfNotExists(clause.Insert{Table: clause.Table{Name: stmt.Table}})
+			stmt.AddClauseIfNotExists(clause.Insert{Table: clause.Table{Name: clause.CurrentTable}})
 			stmt.AddClause(clause.Values{Columns: values.Columns, Values: [][]interface{}{values.Values[0]}})
 			if hasDefaultValues {
 				stmt.AddClauseIfNotExists(clause.Returning{
@@ -81,75 +92,198 @@ func Create(db *gorm.DB) {
 					}).([]clause.Column),
 				})
 			}
-			stmt.Build("INSERT", "VALUES")
-			// 返回自增主键
-			//stmt.Build("INSERT", "VALUES", "RETURNING")
-			//if hasDefaultValues {
-			//	stmt.WriteString(" INTO ")
-			//	for idx, field := range schema.FieldsWithDefaultDBValue {
-			//		if idx > 0 {
-			//			stmt.WriteByte(',')
-			//		}
-			//		boundVars[field.Name] = len(stmt.Vars)
-			//		stmt.AddVar(stmt, sql.Out{Dest: reflect.New(field.FieldType).Interface()})
-			//	}
-			//}
-		}
-
-		if !db.DryRun {
-			for idx, vals := range values.Values {
-				// HACK HACK: replace values one by one, assuming its value layout will be the same all the time, i.e. aligned
-				for idx, val := range vals {
-					switch v := val.(type) {
-					case bool:
-						if v {
-							val = 1
-						} else {
-							val = 0
-						}
+			stmt.Build("INSERT", "VALUES", "RETURNING")
+			if hasDefaultValues {
+				// DM hands generated values back through output binds: RETURNING col INTO ?
+				stmt.WriteString(" INTO ")
+				for idx, field := range schema.FieldsWithDefaultDBValue {
+					if idx > 0 {
+						stmt.WriteByte(',')
 					}
-
-					stmt.Vars[idx] = val
+					boundVars[field.Name] = len(stmt.Vars)
+					stmt.AddVar(stmt, sql.Out{Dest: returningDest(db, field)})
 				}
-				// and then we insert each row one by one then put the returning values back (i.e. last return id => smart insert)
-				// we keep track of the index so that the sub-reflected value is also correct
+			}
+		}
 
-				// BIG BUG: what if any of the transactions failed? some result might already be inserted that dm is so
-				// sneaky that some transaction inserts will exceed the buffer and so will be pushed at unknown point,
-				// resulting in dangling row entries, so we might need to delete them if an error happens
+		if !db.DryRun && db.Error == nil {
+			createRows(db, values, boundVars)
+		}
+	}
+}
 
-				switch result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...); err {
-				case nil: // success
-					db.RowsAffected, _ = result.RowsAffected()
+// createRows executes the built statement once per row, swapping in the values of each row. The rows of a slice
+// are inserted atomically, a failing row rolls back every row inserted before it
+func createRows(db *gorm.DB, values clause.Values, boundVars map[string]int) {
+	stmt := db.Statement
+	schema := stmt.Schema
 
-					insertTo := stmt.ReflectValue
-					switch insertTo.Kind() {
-					case reflect.Slice, reflect.Array:
-						insertTo = insertTo.Index(idx)
-					}
+	finish := func(commit bool) error { return nil }
+	if len(values.Values) > 1 {
+		var err error
+		if finish, err = beginRows(db); err != nil {
+			db.AddError(err)
+			return
+		}
+	}
 
-					if hasDefaultValues {
-						// bind returning value back to reflected value in the respective fields
-						funk.ForEach(
-							funk.Filter(schema.FieldsWithDefaultDBValue, func(field *gormSchema.Field) bool {
-								return funk.Contains(boundVars, field.Name)
-							}),
-							func(field *gormSchema.Field) {
-								switch insertTo.Kind() {
-								case reflect.Struct:
-									if err = field.Set(context.Background(), insertTo, stmt.Vars[boundVars[field.Name]].(sql.Out).Dest); err != nil {
-										db.AddError(err)
-									}
-								case reflect.Map:
-									// todo 设置id的值
-								}
-							},
-						)
-					}
-				default: // failure
-					db.AddError(err)
-				}
+	convert := convertBool
+	if converter, ok := db.Dialector.(varConverter); ok {
+		convert = converter.convertVar
+	}
+
+	var failed bool
+	for idx, vals := range values.Values {
+		// HACK HACK: replace values one by one, assuming its value layout will be the same all the time, i.e. aligned
+		// the swapped values skip BindVarTo, they are converted here
+		for idx, val := range vals {
+			stmt.Vars[idx] = convert(val)
+		}
+
+		// and then we insert each row one by one then put the returning values back (i.e. last return id => smart insert)
+		// we keep track of the index so that the sub-reflected value is also correct
+		result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+		if err != nil {
+			if len(values.Values) > 1 {
+				err = fmt.Errorf("failed to create row #%d: %w", idx, err)
+			}
+			db.AddError(err)
+			failed = true
+			break
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		db.RowsAffected += rowsAffected
+
+		insertTo := stmt.ReflectValue
+		switch insertTo.Kind() {
+		case reflect.Slice, reflect.Array:
+			insertTo = reflect.Indirect(insertTo.Index(idx))
+		}
+
+		// bind returning value back to reflected value in the respective fields
+		for _, field := range schema.FieldsWithDefaultDBValue {
+			if bound, ok := boundVars[field.Name]; ok {
+				dest := stmt.Vars[bound].(sql.Out).Dest
+				setCreatedValue(db, insertTo, field, reflect.ValueOf(dest).Elem().Interface())
 			}
 		}
 	}
+
+	if failed {
+		db.RowsAffected = 0
+	}
+	db.AddError(finish(!failed))
+}
+
+// createRowsSavePoint is the savepoint rows are rolled back to inside a transaction of the caller, creating it again
+// moves it, so a single name is used for every Create
+const createRowsSavePoint = "DM_CREATE_ROWS"
+
+// beginRows makes the rows inserted until finish is called atomic. In the default transaction of gorm a failure
+// rolls the transaction back already, in a transaction of the caller the rows are rolled back to a savepoint,
+// otherwise they get a transaction of their own
+func beginRows(db *gorm.DB) (finish func(commit bool) error, err error) {
+	stmt := db.Statement
+	if _, ok := stmt.ConnPool.(gorm.TxCommitter); ok {
+		if started, _ := db.InstanceGet("gorm:started_transaction"); started == true {
+			return func(bool) error { return nil }, nil
+		}
+		if _, ok := db.Dialector.(gorm.SavePointerDialectorInterface); !ok {
+			return func(bool) error { return nil }, nil
+		}
+
+		tx := db.Session(&gorm.Session{NewDB: true})
+		if err = tx.SavePoint(createRowsSavePoint).Error; err != nil {
+			return nil, err
+		}
+		return func(commit bool) error {
+			if commit {
+				return nil
+			}
+			return tx.RollbackTo(createRowsSavePoint).Error
+		}, nil
+	}
+
+	var tx gorm.ConnPool
+	switch beginner := stmt.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(stmt.Context, nil)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(stmt.Context, nil)
+	default:
+		return func(bool) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pool := stmt.ConnPool
+	stmt.ConnPool = tx
+	return func(commit bool) error {
+		stmt.ConnPool = pool
+		committer, ok := tx.(gorm.TxCommitter)
+		switch {
+		case !ok:
+			return nil
+		case commit:
+			return committer.Commit()
+		default:
+			return committer.Rollback()
+		}
+	}, nil
+}
+
+// createBatch inserts all rows with a single multi-row INSERT, Create only batches rows without values generated by
+// DM, nothing has to be written back
+func createBatch(db *gorm.DB, values clause.Values) {
+	stmt := db.Statement
+	stmt.AddClauseIfNotExists(clause.Insert{Table: clause.Table{Name: clause.CurrentTable}})
+	stmt.AddClause(values)
+	stmt.Build("INSERT", "VALUES")
+
+	if !db.DryRun && db.Error == nil {
+		if result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...); db.AddError(err) == nil {
+			db.RowsAffected, _ = result.RowsAffected()
+		}
+	}
+}
+
+// varConverter converts bound values, it is implemented by Dialector
+type varConverter interface {
+	convertVar(val interface{}) interface{}
+}
+
+// returningDest returns the output bind receiving the value DM generated for field
+func returningDest(db *gorm.DB, field *gormSchema.Field) interface{} {
+	if isUUID(field) {
+		// a UUID comes back as its column type, setCreatedValue parses it
+		if db.Dialector.DataTypeOf(field) == "binary(16)" {
+			return new([]byte)
+		}
+		return new(string)
+	}
+	return reflect.New(field.IndirectFieldType).Interface()
+}
+
+// setCreatedValue copies a value generated by DM into the created struct or map
+func setCreatedValue(db *gorm.DB, insertTo reflect.Value, field *gormSchema.Field, value interface{}) {
+	if isUUIDType(field.IndirectFieldType) {
+		b, err := parseUUID(value)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		value = b
+	}
+
+	switch insertTo.Kind() {
+	case reflect.Struct:
+		db.AddError(field.Set(db.Statement.Context, insertTo, value))
+	case reflect.Map:
+		rv := reflect.ValueOf(value)
+		if insertTo.Type().Key().Kind() == reflect.String && rv.Type().AssignableTo(insertTo.Type().Elem()) {
+			insertTo.SetMapIndex(reflect.ValueOf(field.DBName).Convert(insertTo.Type().Key()), rv)
+		}
+	}
 }
@@ -0,0 +1,264 @@
+package gorm_dm8
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Item struct {
+	ID   int
+	Name string
+}
+
+type Order struct {
+	ID     int
+	Status string
+	Code   string `gorm:"size:36;default:NEWID()"`
+}
+
+// toSQL returns the SQL built by query with its values inlined and its spaces collapsed
+func toSQL(db *gorm.DB, query func(tx *gorm.DB) *gorm.DB) string {
+	return strings.Join(strings.Fields(db.ToSQL(query)), " ")
+}
+
+type Counter struct {
+	ID   int `gorm:"autoIncrementIncrement:2"`
+	Name string
+}
+
+func TestCreateBatchChoice(t *testing.T) {
+	db := dryRun(t, Config{})
+
+	for name, c := range map[string]struct {
+		value  interface{}
+		prefix string
+	}{
+		"batch": {
+			&[]Stock{{SKU: "a", Warehouse: "w"}, {SKU: "b", Warehouse: "w"}},
+			`INSERT INTO "STOCKS" ("SKU","WAREHOUSE","QTY") VALUES ('a','w',0),('b','w',0)`,
+		},
+		"identity": {
+			&[]Item{{Name: "a"}, {Name: "b"}},
+			`INSERT INTO "ITEMS" ("NAME") VALUES ('a') RETURNING "ID" INTO`,
+		},
+		"supplied key": {
+			&[]Item{{ID: 5, Name: "a"}, {Name: "b"}},
+			`INSERT INTO "ITEMS" ("NAME","ID") VALUES ('a',5) RETURNING "ID" INTO`,
+		},
+		"other defaults": {
+			&[]Order{{Status: "paid"}, {}},
+			`INSERT INTO "ORDERS" ("STATUS") VALUES ('paid') RETURNING "CODE","ID" INTO`,
+		},
+	} {
+		if sql := toSQL(db, func(tx *gorm.DB) *gorm.DB { return tx.Create(c.value) }); !strings.HasPrefix(sql, c.prefix) {
+			t.Errorf("expected %v to start with %v, got %v", name, c.prefix, sql)
+		}
+	}
+}
+
+func TestCreateBatch(t *testing.T) {
+	fake := &fakeDB{exec: func(string, []driver.NamedValue) (driver.Result, error) {
+		return driver.RowsAffected(3), nil
+	}}
+	db := fake.open(t, Config{})
+
+	tx := db.Create(&[]Stock{{SKU: "a", Warehouse: "w"}, {SKU: "b", Warehouse: "w"}, {SKU: "c", Warehouse: "w"}})
+	if tx.Error != nil || tx.RowsAffected != 3 {
+		t.Fatalf("failed to create stocks, got %v, %v", tx.RowsAffected, tx.Error)
+	}
+
+	expected := []string{`INSERT INTO "STOCKS" ("SKU","WAREHOUSE","QTY") VALUES (?,?,?),(?,?,?),(?,?,?)`}
+	if statements := fake.statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected %v, got %v", expected, statements)
+	}
+}
+
+func TestCreateIdentityIncrement(t *testing.T) {
+	db := dryRun(t, Config{})
+	if dataType := db.Dialector.DataTypeOf(parseModel(t, db, &Counter{}).Schema.LookUpField("ID")); dataType != "bigint IDENTITY(1,2)" {
+		t.Errorf("expected the identity to step by 2, got %v", dataType)
+	}
+
+	// the identities come from the table, the session inserting concurrently took 12
+	identities := []int{10, 14}
+	fake := &fakeDB{exec: func(query string, args []driver.NamedValue) (driver.Result, error) {
+		if strings.HasPrefix(query, "INSERT") {
+			*args[len(args)-1].Value.(sql.Out).Dest.(*int), identities = identities[0], identities[1:]
+		}
+		return driver.RowsAffected(1), nil
+	}}
+
+	counters := []Counter{{Name: "a"}, {Name: "b"}}
+	if err := fake.open(t, Config{}).Create(&counters).Error; err != nil {
+		t.Fatalf("failed to create counters, got error %v", err)
+	}
+	if counters[0].ID != 10 || counters[1].ID != 14 {
+		t.Errorf("expected the identities returned by each insert, got %+v", counters)
+	}
+}
+
+// returningDB opens a db filling the output binds of its inserts, the n-th insert returns identity n and code
+// c<n>, the insert numbered fail fails
+func returningDB(t *testing.T, fail int) (*gorm.DB, *fakeDB) {
+	var inserts int
+	fake := &fakeDB{exec: func(query string, args []driver.NamedValue) (driver.Result, error) {
+		if !strings.HasPrefix(query, "INSERT") {
+			return driver.RowsAffected(0), nil
+		}
+		if inserts++; inserts == fail {
+			return nil, errors.New("unique constraint violated")
+		}
+		for _, arg := range args {
+			switch dest := arg.Value.(type) {
+			case sql.Out:
+				switch v := dest.Dest.(type) {
+				case *int:
+					*v = inserts
+				case *string:
+					*v = fmt.Sprintf("c%d", inserts)
+				}
+			}
+		}
+		return driver.RowsAffected(1), nil
+	}}
+	return fake.open(t, Config{}), fake
+}
+
+func TestCreateReturning(t *testing.T) {
+	db, fake := returningDB(t, 0)
+
+	orders := []Order{{Status: "a"}, {Status: "b"}}
+	if err := db.Create(&orders).Error; err != nil {
+		t.Fatalf("failed to create orders, got error %v", err)
+	}
+	if !reflect.DeepEqual(orders, []Order{{ID: 1, Status: "a", Code: "c1"}, {ID: 2, Status: "b", Code: "c2"}}) {
+		t.Errorf("expected generated values to be set, got %+v", orders)
+	}
+	insert := `INSERT INTO "ORDERS" ("STATUS") VALUES (?) RETURNING "CODE","ID" INTO ?,?`
+	if statements := fake.statements(); !reflect.DeepEqual(statements, []string{"BEGIN", insert, insert, "COMMIT"}) {
+		t.Errorf("expected the rows to be inserted in a transaction, got %v", statements)
+	}
+
+	values := map[string]interface{}{"Status": "c"}
+	if err := db.Model(&Order{}).Create(values).Error; err != nil {
+		t.Fatalf("failed to create map, got error %v", err)
+	}
+	if values["ID"] != 3 || values["CODE"] != "c3" {
+		t.Errorf("expected generated values in the map, got %v", values)
+	}
+}
+
+func TestCreateRowsRollback(t *testing.T) {
+	db, fake := returningDB(t, 2)
+	tx := db.Create(&[]Order{{Status: "a"}, {Status: "b"}, {Status: "c"}})
+	if tx.Error == nil || !strings.Contains(tx.Error.Error(), "failed to create row #1") || tx.RowsAffected != 0 {
+		t.Errorf("expected row #1 to fail with nothing inserted, got %v, %v", tx.Error, tx.RowsAffected)
+	}
+	if statements := fake.statements(); len(statements) != 4 || statements[0] != "BEGIN" || statements[3] != "ROLLBACK" {
+		t.Errorf("expected the insert transaction to be rolled back, got %v", statements)
+	}
+
+	insert := `INSERT INTO "ORDERS" ("STATUS") VALUES (?) RETURNING "CODE","ID" INTO ?,?`
+	db, fake = returningDB(t, 2)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&[]Order{{Status: "a"}, {Status: "b"}}).Error; err == nil {
+			t.Errorf("expected row #1 to fail")
+		}
+		return tx.Create(&Order{Status: "c"}).Error
+	})
+	if err != nil {
+		t.Fatalf("failed to run transaction, got error %v", err)
+	}
+
+	expected := []string{"BEGIN", "SAVEPOINT DM_CREATE_ROWS", insert, insert, "ROLLBACK TO SAVEPOINT DM_CREATE_ROWS", insert, "COMMIT"}
+	if statements := fake.statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected the rows to be rolled back to a savepoint, got %v", statements)
+	}
+
+	// the default transaction of gorm is rolled back as a whole, it needs no savepoint
+	_, fake = returningDB(t, 2)
+	db, err = gorm.Open(New(Config{Conn: sql.OpenDB(fake)}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+	if err = db.Create(&[]Order{{Status: "a"}, {Status: "b"}}).Error; err == nil {
+		t.Errorf("expected row #1 to fail")
+	}
+	if statements := fake.statements(); !reflect.DeepEqual(statements, []string{"BEGIN", insert, insert, "ROLLBACK"}) {
+		t.Errorf("expected the default transaction to be rolled back without savepoint, got %v", statements)
+	}
+}
+
+type Stock struct {
+	SKU       string `gorm:"primaryKey;size:20"`
+	Warehouse string `gorm:"primaryKey;size:20"`
+	Qty       int
+}
+
+func TestCreateOnConflict(t *testing.T) {
+	db := dryRun(t, Config{})
+
+	for name, c := range map[string]struct {
+		query    func(tx *gorm.DB) *gorm.DB
+		expected string
+	}{
+		"update all": {
+			func(tx *gorm.DB) *gorm.DB {
+				return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&Item{ID: 1, Name: "a"})
+			},
+			`MERGE INTO "ITEMS" USING (SELECT 'a' AS "NAME",1 AS "ID" FROM DUAL) "EXCLUDE" ON ("ITEMS"."ID" = "EXCLUDE"."ID") ` +
+				`WHEN MATCHED THEN UPDATE SET "NAME"="EXCLUDE"."NAME" ` +
+				`WHEN NOT MATCHED THEN INSERT ("NAME","ID") VALUES ("EXCLUDE"."NAME","EXCLUDE"."ID")`,
+		},
+		"slice": {
+			func(tx *gorm.DB) *gorm.DB {
+				return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&[]Item{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}})
+			},
+			`MERGE INTO "ITEMS" USING (SELECT 'a' AS "NAME",1 AS "ID" FROM DUAL UNION ALL SELECT 'b' AS "NAME",2 AS "ID" FROM DUAL) "EXCLUDE" ` +
+				`ON ("ITEMS"."ID" = "EXCLUDE"."ID") WHEN MATCHED THEN UPDATE SET "NAME"="EXCLUDE"."NAME" ` +
+				`WHEN NOT MATCHED THEN INSERT ("NAME","ID") VALUES ("EXCLUDE"."NAME","EXCLUDE"."ID")`,
+		},
+		"composite key": {
+			func(tx *gorm.DB) *gorm.DB {
+				return tx.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"qty"})}).Create(&Stock{SKU: "s", Warehouse: "w", Qty: 3})
+			},
+			`MERGE INTO "STOCKS" USING (SELECT 's' AS "SKU",'w' AS "WAREHOUSE",3 AS "QTY" FROM DUAL) "EXCLUDE" ` +
+				`ON ("STOCKS"."SKU" = "EXCLUDE"."SKU" AND "STOCKS"."WAREHOUSE" = "EXCLUDE"."WAREHOUSE") ` +
+				`WHEN MATCHED THEN UPDATE SET "QTY"="EXCLUDE"."QTY" ` +
+				`WHEN NOT MATCHED THEN INSERT ("SKU","WAREHOUSE","QTY") VALUES ("EXCLUDE"."SKU","EXCLUDE"."WAREHOUSE","EXCLUDE"."QTY")`,
+		},
+		"do nothing": {
+			func(tx *gorm.DB) *gorm.DB {
+				return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&Stock{SKU: "s", Warehouse: "w", Qty: 3})
+			},
+			`MERGE INTO "STOCKS" USING (SELECT 's' AS "SKU",'w' AS "WAREHOUSE",3 AS "QTY" FROM DUAL) "EXCLUDE" ` +
+				`ON ("STOCKS"."SKU" = "EXCLUDE"."SKU" AND "STOCKS"."WAREHOUSE" = "EXCLUDE"."WAREHOUSE") ` +
+				`WHEN NOT MATCHED THEN INSERT ("SKU","WAREHOUSE","QTY") VALUES ("EXCLUDE"."SKU","EXCLUDE"."WAREHOUSE","EXCLUDE"."QTY")`,
+		},
+	} {
+		if sql := toSQL(db, c.query); sql != c.expected {
+			t.Errorf("expected %v for %v, got %v", c.expected, name, sql)
+		}
+	}
+
+	// rows getting a new identity can't conflict, they are inserted one by one to return it
+	generated := toSQL(db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&[]Item{{Name: "a"}, {Name: "b"}})
+	})
+	if expected := `INSERT INTO "ITEMS" ("NAME") VALUES ('a') RETURNING "ID" INTO`; !strings.HasPrefix(generated, expected) {
+		t.Errorf("expected %v for generated key, got %v", expected, generated)
+	}
+
+	err := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, UpdateAll: true}).Create(&Order{Status: "a"}).Error
+	if err == nil || !strings.Contains(err.Error(), "ON CONFLICT column CODE is not inserted") {
+		t.Errorf("expected an upsert on a column which isn't inserted to fail, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"gorm.io/gorm/schema"
+)
+
+// decimalTypeOf returns the DECIMAL type of the field, honouring its precision and scale tags
+func decimalTypeOf(field *schema.Field, defaultPrecision int) string {
+	switch {
+	case field.Precision > 0:
+		return fmt.Sprintf("DECIMAL(%d, %d)", field.Precision, field.Scale)
+	case defaultPrecision > 0:
+		return fmt.Sprintf("DECIMAL(%d, %d)", defaultPrecision, field.Scale)
+	}
+	return "DECIMAL"
+}
+
+// isDecimal reports whether the field holds a decimal number type such as shopspring's decimal.Decimal, a struct
+// whose driver.Valuer hands out the number as string. Nullable wrappers, e.g. decimal.NullDecimal, are recognized
+// by their first field like gorm does for sql.NullString
+func isDecimal(field *schema.Field) bool {
+	if _, ok := field.TagSettings["TYPE"]; ok {
+		return false
+	}
+	return isDecimalType(field.IndirectFieldType)
+}
+
+func isDecimalType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	fieldValue := reflect.New(typ).Interface()
+	if _, ok := fieldValue.(schema.GormDataTypeInterface); ok {
+		return false
+	}
+	valuer, ok := fieldValue.(driver.Valuer)
+	if !ok {
+		return false
+	}
+
+	switch v, err := valuer.Value(); {
+	case err != nil:
+		return false
+	case v == nil:
+		return typ.NumField() > 0 && isDecimalType(typ.Field(0).Type)
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		_, err = strconv.ParseFloat(s, 64)
+		return err == nil
+	}
+}
@@ -0,0 +1,68 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+type testDecimal struct {
+	value string
+}
+
+func (d testDecimal) Value() (driver.Value, error) {
+	if d.value == "" {
+		return "0", nil
+	}
+	return d.value, nil
+}
+
+type testNullDecimal struct {
+	Decimal testDecimal
+	Valid   bool
+}
+
+func (d testNullDecimal) Value() (driver.Value, error) {
+	if !d.Valid {
+		return nil, nil
+	}
+	return d.Decimal.Value()
+}
+
+type Account struct {
+	ID       uint64 `gorm:"primaryKey"`
+	Balance  testDecimal
+	Price    testDecimal `gorm:"precision:10;scale:2"`
+	Discount testNullDecimal
+	Cents    int64  `gorm:"precision:18"`
+	Number   uint64 `gorm:"type:varchar(20)"`
+	Version  uint64
+	Count    uint32
+	Rate     float64 `gorm:"precision:6;scale:4"`
+}
+
+func TestDataTypeOfNumbers(t *testing.T) {
+	s, err := schema.Parse(&Account{}, &sync.Map{}, Namer{})
+	if err != nil {
+		t.Fatalf("failed to parse schema, got error %v", err)
+	}
+
+	dialector := Dialector{Config: &Config{}}
+	for name, expected := range map[string]string{
+		"ID":       "bigint IDENTITY(1,1)",
+		"Balance":  "DECIMAL",
+		"Price":    "DECIMAL(10, 2)",
+		"Discount": "DECIMAL",
+		"Cents":    "DECIMAL(18, 0)",
+		"Number":   "varchar(20)",
+		"Version":  "DECIMAL(20, 0)",
+		"Count":    "bigint",
+		"Rate":     "DECIMAL(6, 4)",
+	} {
+		if dataType := dialector.DataTypeOf(s.LookUpField(name)); dataType != expected {
+			t.Errorf("expected data type %v for %v, got %v", expected, name, dataType)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package gorm_dm8
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	_ "gitee.com/chunanyong/dm"
+	"github.com/thoas/go-funk"
 	"github.com/ximenhaoziye/gorm-dm8/clauses"
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
@@ -11,11 +13,10 @@ import (
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
-	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"unsafe"
+	"time"
 )
 
 type Config struct {
@@ -23,6 +24,22 @@ type Config struct {
 	DSN               string
 	DefaultStringSize int
 	Conn              gorm.ConnPool
+	// IdentifierCase folds names of tables, columns and other objects, UpperCase by default
+	IdentifierCase IdentifierCase
+	// TimeType is the type of time columns without a timezone tag, DateTime by default
+	TimeType TimeType
+	// TimeZone is set as session time zone of every connection opened from DSN when not nil, it can't be used with
+	// Conn. DM takes an offset, the one of TimeZone when the connection is made, so a connection kept across a
+	// daylight saving change keeps the old offset, sql.DB.SetConnMaxLifetime renews them
+	TimeZone *time.Location
+	// UUIDType is the type of UUID columns, UUIDAsString by default
+	UUIDType UUIDType
+	// Schema holds the tables named by the naming strategy instead of the current schema, a table of another schema
+	// can be named "SCHEMA.TABLE" by TableName()
+	Schema string
+	// EscapeReservedWords appends an underscore to table and column names which are DM reserved words, off by default
+	// as every name is quoted
+	EscapeReservedWords bool
 }
 
 type Dialector struct {
@@ -34,7 +51,15 @@ func (d Dialector) Name() string {
 }
 
 func (d Dialector) Initialize(db *gorm.DB) (err error) {
-	db.NamingStrategy = Namer{}
+	if _, ok := db.NamingStrategy.(Namer); !ok {
+		namer := Namer{Case: d.IdentifierCase, Schema: d.Schema, EscapeReservedWords: d.EscapeReservedWords}
+		if strategy, ok := db.NamingStrategy.(schema.NamingStrategy); ok {
+			namer.NamingStrategy = strategy
+		} else {
+			namer.Strategy = db.NamingStrategy
+		}
+		db.NamingStrategy = namer
+	}
 	// register callbacks
 	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
 
@@ -43,16 +68,35 @@ func (d Dialector) Initialize(db *gorm.DB) (err error) {
 	}
 
 	if d.Conn != nil {
+		if d.TimeZone != nil {
+			return ErrTimeZoneWithConn
+		}
 		db.ConnPool = d.Conn
 	} else {
-		db.ConnPool, err = sql.Open(d.DriverName, d.DSN)
-		if err != nil {
+		var connector driver.Connector
+		if connector, err = openConnector(d.DriverName, d.DSN); err != nil {
 			return err
 		}
+		if d.TimeZone != nil {
+			connector = sessionConnector{Connector: connector, location: d.TimeZone}
+		}
+		db.ConnPool = sql.OpenDB(clobConnector{Connector: connector})
+	}
+	if err = db.Callback().Create().Replace("gorm:create", Create); err != nil {
+		return
+	}
+	if err = db.Callback().Raw().Before("gorm:raw").Register("dm:merge", Merge); err != nil {
+		return
+	}
+	if err = db.Callback().Raw().Before("gorm:raw").After("dm:merge").Register("dm:fold", d.FoldRawSQL); err != nil {
+		return
+	}
+	if err = db.Callback().Row().Before("gorm:row").Register("dm:fold", d.FoldRawSQL); err != nil {
+		return
+	}
+	if err = db.Callback().Query().Before("gorm:query").Register("dm:fold", d.FoldRawSQL); err != nil {
+		return
 	}
-	//if err = db.Callback().Create().Replace("gorm:create", Create); err != nil {
-	//	return
-	//}
 	for k, v := range d.ClauseBuilders() {
 		db.ClauseBuilders[k] = v
 	}
@@ -99,18 +143,20 @@ func (d Dialector) Migrator(db *gorm.DB) gorm.Migrator {
 	}
 }
 func (d Dialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
-	p := unsafe.Pointer(&stmt.SQL)
-	type Builderp struct {
-		Addr *clause.Builder // of receiver, to detect copies by value
-		Buf  []byte
+	// v has just been appended to stmt.Vars, every bound value of inserts, updates and conditions passes here
+	if idx := len(stmt.Vars) - 1; idx >= 0 {
+		stmt.Vars[idx] = d.convertVar(stmt.Vars[idx])
 	}
-	(*Builderp)(p).Buf = []byte(strings.ToUpper(stmt.SQL.String()))
 	writer.WriteString("?")
-	//writer.WriteString(strconv.Itoa(len(stmt.Vars)))
+}
+
+// convertVar converts a bound value to what DM expects, bools and UUIDs need converting
+func (d Dialector) convertVar(val interface{}) interface{} {
+	return d.convertUUID(convertBool(val))
 }
 
 func (d Dialector) QuoteTo(writer clause.Writer, str string) {
-	str = strings.ToUpper(str)
+	str = d.IdentifierCase.Convert(str)
 	var (
 		underQuoted, selfQuoted bool
 		continuousBacktick      int8
@@ -160,14 +206,39 @@ func (d Dialector) QuoteTo(writer clause.Writer, str string) {
 var numericPlaceholder = regexp.MustCompile("@p(\\d+)")
 
 func (d Dialector) Explain(sql string, vars ...interface{}) string {
-	return logger.ExplainSQL(sql, nil, `'`, vars...)
+	// vars may be the slice of the caller, e.g. Statement.Vars, convert a copy
+	converted := make([]interface{}, len(vars))
+	for idx, v := range vars {
+		converted[idx] = d.convertVar(v)
+	}
+	return logger.ExplainSQL(sql, nil, `'`, converted...)
 }
 
 func (d Dialector) DataTypeOf(field *schema.Field) string {
+	if isDecimal(field) {
+		return decimalTypeOf(field, 0)
+	}
+	if isUUID(field) {
+		return d.uuidTypeOf(field)
+	}
+	if strings.EqualFold(string(field.DataType), "interval") {
+		if isInterval(field) {
+			return intervalTypeOf(field)
+		}
+		// a time.Duration tagged type:interval binds and scans nanoseconds, the migrator refuses it
+		return "bigint"
+	}
+	if geometryType := geometryTypeOf(field); geometryType != "" {
+		return geometryType
+	}
+
 	switch field.DataType {
 	case schema.Bool:
 		return "bit"
 	case schema.Int, schema.Uint:
+		if field.Precision > 0 && !field.AutoIncrement {
+			return decimalTypeOf(field, 0)
+		}
 		var sqlType string
 		switch {
 		case field.Size < 8:
@@ -176,16 +247,23 @@ func (d Dialector) DataTypeOf(field *schema.Field) string {
 			sqlType = "smallint"
 		case field.Size < 32:
 			sqlType = "int"
+		case field.DataType == schema.Uint && field.Size >= 64 && !field.AutoIncrement:
+			// bigint is signed, the largest uint64 has 20 digits
+			return decimalTypeOf(field, 20)
 		default:
 			sqlType = "bigint"
 		}
 		if field.AutoIncrement {
-			return sqlType + " IDENTITY(1,1)"
+			increment := field.AutoIncrementIncrement
+			if increment == 0 {
+				increment = 1
+			}
+			return fmt.Sprintf("%s IDENTITY(1,%d)", sqlType, increment)
 		}
 		return sqlType
 	case schema.Float:
 		if field.Precision > 0 {
-			return fmt.Sprintf("DECIMAL(%d, %d)", field.Precision, field.Scale)
+			return decimalTypeOf(field, 0)
 		}
 
 		if field.Size <= 32 {
@@ -203,21 +281,13 @@ func (d Dialector) DataTypeOf(field *schema.Field) string {
 				size = 256
 			}
 		}
-		if size > 0 && size <= 8188 {
-			return fmt.Sprintf("varchar(%d)", size)
+		if size == 0 || size > 8188 {
+			// CLOB columns can't be indexed, keys and indexed strings got a size above, = on CLOB is rewritten
+			return "clob"
 		}
-		// TODO :text\clob 使用不了
-		return "varchar(8188)"
+		return fmt.Sprintf("varchar(%d)", size)
 	case schema.Time:
-		precision := ""
-		if field.Precision > 0 {
-			precision = fmt.Sprintf("(%d)", field.Precision)
-		}
-
-		if field.NotNull || field.PrimaryKey {
-			return "datetime" + precision
-		}
-		return "datetime" + precision + " NULL"
+		return d.timeTypeOf(field)
 	case schema.Bytes:
 		if field.Size > 0 && field.Size < 65536 {
 			return fmt.Sprintf("binary(%d)", field.Size)
@@ -239,6 +309,10 @@ func (d Dialector) RollbackTo(tx *gorm.DB, name string) error {
 func (d Dialector) RewriteWhere(c clause.Clause, builder clause.Builder) {
 	if where, ok := c.Expression.(clause.Where); ok {
 		builder.WriteString(" WHERE ")
+		where.Exprs = d.IdentifierCase.foldExpressions(where.Exprs)
+		if stmt, ok := builder.(*gorm.Statement); ok {
+			where.Exprs = d.compareClobs(stmt, where.Exprs)
+		}
 
 		// Switch position if the first query expression is a single Or condition
 		for idx, expr := range where.Exprs {
@@ -353,7 +427,7 @@ func (d Dialector) RewriteSet(c clause.Clause, builder clause.Builder) {
 				if i > 0 {
 					builder.WriteByte(',')
 				}
-				assignment.Column.Name = strings.ToUpper(assignment.Column.Name)
+				assignment.Column.Name = d.IdentifierCase.Convert(assignment.Column.Name)
 				builder.WriteQuoted(assignment.Column)
 				builder.WriteByte('=')
 				builder.AddVar(builder, assignment.Value)
@@ -368,47 +442,62 @@ func (d Dialector) RewriteSet(c clause.Clause, builder clause.Builder) {
 }
 
 func (d Dialector) RewriteConfict(c clause.Clause, builder clause.Builder) {
-	//stmt, _ := builder.(*gorm.Statement)
-	//values := callbacks.ConvertToCreateValues(stmt)
-	//s := stmt.Schema
-	//fx := funk.Contains(
-	//	funk.Map(values.Columns, func(c clause.Column) string { return c.Name }),
-	//	funk.Map(s.PrimaryFields, func(field *schema.Field) string { return field.DBName }),
-	//)
-	//if onConflict, ok := c.Expression.(clause.OnConflict); ok && fx {
-	//	stmt.AddClauseIfNotExists(clauses.Merge{
-	//		Using: []clause.Interface{
-	//			clause.Select{
-	//				Columns: funk.Map(values.Columns, func(column clause.Column) clause.Column {
-	//					// HACK: I can not come up with a better alternative for now
-	//					// I want to add a value to the list of variable and then capture the bind variable position as well
-	//					buf := bytes.NewBufferString("")
-	//					stmt.Vars = append(stmt.Vars, values.Values[0][funk.IndexOf(values.Columns, column)])
-	//					stmt.BindVarTo(buf, stmt, nil)
-	//
-	//					column.Alias = column.Name
-	//					// then the captured bind var will be the name
-	//					column.Name = buf.String()
-	//					return column
-	//				}).([]clause.Column),
-	//			},
-	//			clause.From{
-	//				Tables: []clause.Table{{Name: d.DummyTableName()}},
-	//			},
-	//		},
-	//		On: funk.Map(s.PrimaryFields, func(field *schema.Field) clause.Expression {
-	//			return clause.Eq{
-	//				Column: clause.Column{Table: stmt.Table, Name: field.DBName},
-	//				Value:  clause.Column{Table: clauses.MergeDefaultExcludeName(), Name: field.DBName},
-	//			}
-	//		}).([]clause.Expression),
-	//	})
-	//	stmt.AddClauseIfNotExists(clauses.WhenMatched{Set: onConflict.DoUpdates})
-	//	stmt.AddClauseIfNotExists(clauses.WhenNotMatched{Values: values})
-	//
-	//	stmt.Build("MERGE", "WHEN MATCHED", "WHEN NOT MATCHED")
-	//}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	if !ok {
+		return
+	}
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok {
+		return
+	}
+	values, ok := stmt.Clauses["VALUES"].Expression.(clause.Values)
+	if !ok || len(values.Columns) == 0 {
+		return
+	}
+
+	// DM has no ON CONFLICT, the upsert is a MERGE of the inserted values into the table
+	merge := clauses.Merge{
+		Table: clause.Table{Name: clause.CurrentTable},
+		Using: []clause.Interface{clauses.Dual{Values: values}},
+		Alias: clauses.MergeDefaultExcludeName(),
+	}
+	exclude := merge.SourceAlias()
+	for _, column := range onConflict.Columns {
+		merge.On = append(merge.On, clause.Eq{
+			Column: clause.Column{Table: stmt.Table, Name: column.Name},
+			Value:  clause.Column{Table: exclude, Name: column.Name},
+		})
+	}
+	stmt.AddClause(merge)
+
+	if !onConflict.DoNothing {
+		set := make(clause.Set, 0, len(onConflict.DoUpdates))
+		for _, assignment := range onConflict.DoUpdates {
+			// columns referenced in the ON condition can not be updated
+			if funk.Find(onConflict.Columns, func(c clause.Column) bool {
+				return strings.EqualFold(c.Name, assignment.Column.Name)
+			}) != nil {
+				continue
+			}
+			// clause.AssignmentColumns refers to the values as excluded
+			if column, ok := assignment.Value.(clause.Column); ok && column.Table == "excluded" {
+				column.Table = exclude
+				assignment.Value = column
+			}
+			set = append(set, assignment)
+		}
+		if len(set) > 0 {
+			stmt.AddClause(clauses.WhenMatched{Set: set, Where: onConflict.Where})
+		}
+	}
+
+	insert := make([]interface{}, len(values.Columns))
+	for idx, column := range values.Columns {
+		insert[idx] = clause.Column{Table: exclude, Name: column.Name}
+	}
+	stmt.AddClause(clauses.WhenNotMatched{Values: clause.Values{Columns: values.Columns, Values: [][]interface{}{insert}}})
 
+	stmt.Build("MERGE", "WHEN MATCHED", "WHEN NOT MATCHED")
 }
 
 func (d Dialector) RewriteGroupby(c clause.Clause, builder clause.Builder) {
@@ -418,13 +507,13 @@ func (d Dialector) RewriteGroupby(c clause.Clause, builder clause.Builder) {
 			if idx > 0 {
 				builder.WriteByte(',')
 			}
-			column.Name = strings.ToUpper(column.Name)
+			column.Name = d.IdentifierCase.Convert(column.Name)
 			builder.WriteQuoted(column)
 		}
 
 		if len(groupBy.Having) > 0 {
 			builder.WriteString(" HAVING ")
-			clause.Where{Exprs: groupBy.Having}.Build(builder)
+			clause.Where{Exprs: d.IdentifierCase.foldExpressions(groupBy.Having)}.Build(builder)
 		}
 	}
 }
@@ -437,7 +526,7 @@ func (d Dialector) RewriteOrderby(c clause.Clause, builder clause.Builder) {
 				builder.WriteByte(',')
 			}
 			if !isPkStr(column.Column.Name) {
-				column.Column.Name = strings.ToUpper(column.Column.Name)
+				column.Column.Name = d.IdentifierCase.Convert(column.Column.Name)
 			}
 			builder.WriteQuoted(column.Column)
 			if column.Desc {
@@ -459,13 +548,14 @@ func (d Dialector) RewriteSelect(c clause.Clause, builder clause.Builder) {
 				if idx > 0 {
 					builder.WriteByte(',')
 				}
-				column.Name = strings.ToUpper(column.Name)
+				column.Name = d.IdentifierCase.Convert(column.Name)
 				builder.WriteQuoted(column)
 			}
 		} else {
 			builder.WriteByte('*')
 		}
 	} else {
+		c.Expression = d.IdentifierCase.foldExpression(c.Expression)
 		c.Build(builder)
 	}
 }
@@ -478,7 +568,7 @@ func (d Dialector) RewriteFrom(c clause.Clause, builder clause.Builder) {
 				if idx > 0 {
 					builder.WriteByte(',')
 				}
-				table.Name = strings.ToUpper(table.Name)
+				table.Name = d.IdentifierCase.Convert(table.Name)
 				builder.WriteQuoted(table)
 			}
 		} else {
@@ -486,12 +576,7 @@ func (d Dialector) RewriteFrom(c clause.Clause, builder clause.Builder) {
 		}
 		for _, join := range from.Joins {
 			if join.Expression != nil {
-				v := reflect.ValueOf(&join.Expression).Elem()
-				sqlstr := v.Elem().FieldByName("SQL").String()
-				tmp := reflect.New(v.Elem().Type()).Elem()
-				tmp.Set(v.Elem())
-				tmp.FieldByName("SQL").SetString(strings.ToUpper(sqlstr))
-				v.Set(tmp)
+				join.Expression = d.IdentifierCase.foldExpression(join.Expression)
 			}
 			builder.WriteByte(' ')
 			join.Build(builder)
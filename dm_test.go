@@ -1,17 +1,230 @@
 package gorm_dm8
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
+	"io"
 	"log"
 	"os"
+	"sync"
 	"testing"
 	"time"
 )
 
 var db *gorm.DB
 
+// dryRun opens a db building SQL without running it, a nil Conn of config is replaced by an unconnected one
+func dryRun(t *testing.T, config Config) *gorm.DB {
+	if config.Conn == nil {
+		config.Conn = &sql.DB{}
+	}
+	tx, err := gorm.Open(New(config), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open dry run db: %v", err)
+	}
+	return tx
+}
+
+// sqlRecorder is a logger keeping the SQL of every statement, dry run statements are logged too
+type sqlRecorder struct {
+	gormlogger.Interface
+	sqls []string
+}
+
+func (r *sqlRecorder) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return r
+}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.sqls = append(r.sqls, sql)
+}
+
+// record makes db log into a new sqlRecorder
+func record(db *gorm.DB) (*gorm.DB, *sqlRecorder) {
+	recorder := &sqlRecorder{Interface: gormlogger.Discard}
+	return db.Session(&gorm.Session{Logger: recorder}), recorder
+}
+
+// fakeDB is a database/sql connector recording the statements run on it, transactions included as BEGIN, COMMIT
+// and ROLLBACK. exec and query answer the statements, by default an exec affects one row and a query returns no row
+type fakeDB struct {
+	mu    sync.Mutex
+	sqls  []string
+	exec  func(query string, args []driver.NamedValue) (driver.Result, error)
+	query func(query string, args []driver.NamedValue) (columns []string, rows [][]driver.Value, err error)
+}
+
+// open opens a db running its statements on the fakeDB
+func (f *fakeDB) open(t *testing.T, config Config) *gorm.DB {
+	config.Conn = sql.OpenDB(f)
+	tx, err := gorm.Open(New(config), &gorm.Config{DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open fake db: %v", err)
+	}
+	return tx
+}
+
+// fakeDriver is registered as dm-fake, it connects to the fakeDB of the test and records the DSN it is given
+type fakeDriver struct {
+	fake *fakeDB
+	dsn  string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("connections are made by the connector")
+}
+
+func (d *fakeDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	d.dsn = dsn
+	return fakeConnector{fakeDB: d.fake, driver: d}, nil
+}
+
+// fakeConnector is the connector of fakeDriver, sql.DB.Driver returns the driver of the connector
+type fakeConnector struct {
+	*fakeDB
+	driver driver.Driver
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("dm-fake", testDriver)
+}
+
+// openDriver opens a db on the fakeDB through the driver dm-fake, as Initialize opens a DSN
+func (f *fakeDB) openDriver(t *testing.T, config Config) *gorm.DB {
+	testDriver.fake = f
+	config.DriverName = "dm-fake"
+	if config.DSN == "" {
+		config.DSN = "dm://SYSDBA:SYSDBA@localhost:5236"
+	}
+	tx, err := gorm.Open(New(config), &gorm.Config{DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open fake driver: %v", err)
+	}
+	return tx
+}
+
+func (f *fakeDB) record(query string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sqls = append(f.sqls, query)
+}
+
+// statements returns the recorded statements
+func (f *fakeDB) statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sqls...)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{f}, nil
+}
+
+func (f *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return fakeTx(c), nil
+}
+
+// CheckNamedValue passes every value as is, sql.Out included
+func (c fakeConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query)
+	if c.db.exec != nil {
+		return c.db.exec(query, args)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query)
+	rows := &fakeRows{}
+	if c.db.query != nil {
+		var err error
+		if rows.columns, rows.rows, err = c.db.query(query, args); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+type fakeTx fakeConn
+
+func (tx fakeTx) Commit() error {
+	tx.db.record("COMMIT")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK")
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+// Next returns the error of a row holding one, to fail in the middle of the rows
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	if err, ok := r.rows[0][0].(error); ok {
+		return err
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// parseModel parses the schema of model into a statement of db
+func parseModel(t *testing.T, db *gorm.DB, model interface{}) *gorm.Statement {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		t.Fatalf("failed to parse schema, got error %v", err)
+	}
+	return stmt
+}
+
 type MenusUser struct {
 	UserID int `gorm:"not null;index" json:"user_id" form:"user_id" uri:"user_id"`
 	MenuID int `json:"menu_id" form:"menu_id" uri:"menu_id" gorm:"index"`
@@ -66,3 +279,59 @@ func TestDB(t *testing.T) {
 	user.Name = "weihao"
 	db.Save(&user)
 }
+
+type TimeZoneEvent struct {
+	ID        int
+	StartedAt time.Time `gorm:"timezone;precision:6"`
+}
+
+// testDSN returns the DSN of the DM database given by DM_DSN, the test is skipped without it
+func testDSN(t *testing.T) string {
+	dsn := os.Getenv("DM_DSN")
+	if dsn == "" {
+		t.Skip("DM_DSN is not set, e.g. dm://SYSDBA:SYSDBA@localhost:5236")
+	}
+	return dsn
+}
+
+func TestDBTimeZone(t *testing.T) {
+	dsn := testDSN(t)
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("failed to load time zone, got error %v", err)
+	}
+	tdb, err := gorm.Open(New(Config{DSN: dsn, TimeZone: shanghai}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open %v, got error %v", dsn, err)
+	}
+	if err = tdb.Migrator().DropTable(&TimeZoneEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = tdb.AutoMigrate(&TimeZoneEvent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("failed to load time zone, got error %v", err)
+	}
+	for _, startedAt := range []time.Time{
+		time.Date(2023, 6, 1, 8, 30, 0, 123456000, newYork),
+		time.Date(2023, 6, 1, 8, 30, 0, 0, time.UTC),
+		time.Date(2023, 6, 1, 8, 30, 0, 0, shanghai),
+	} {
+		event := TimeZoneEvent{StartedAt: startedAt}
+		if err = tdb.Create(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		var result TimeZoneEvent
+		if err = tdb.First(&result, event.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		_, expectedOffset := startedAt.Zone()
+		if _, offset := result.StartedAt.Zone(); !result.StartedAt.Equal(startedAt) || offset != expectedOffset {
+			t.Errorf("expected %v, got %v", startedAt, result.StartedAt)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package gorm_dm8
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// foldSQL converts the unquoted identifiers and keywords of sql to upper case, string literals, quoted
+// identifiers, comments, numbers and named parameters are copied as is. DM folds unquoted names to upper case
+// itself, so with PreserveCase and LowerCase sql is kept as written and has to quote its lower or mixed case names
+func (c IdentifierCase) foldSQL(sql string) string {
+	if c != UpperCase {
+		return sql
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(sql))
+
+	for i := 0; i < len(sql); {
+		switch ch := sql[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			// literal or quoted identifier, a doubled quote escapes the quote
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == ch {
+					if j+1 < len(sql) && sql[j+1] == ch {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			builder.WriteString(sql[i:j])
+			i = j
+		case ch == '-' && strings.HasPrefix(sql[i:], "--"):
+			j := strings.IndexByte(sql[i:], '\n')
+			if j < 0 {
+				j = len(sql) - i
+			}
+			builder.WriteString(sql[i : i+j])
+			i += j
+		case ch == '/' && strings.HasPrefix(sql[i:], "/*"):
+			j := strings.Index(sql[i+2:], "*/")
+			if j < 0 {
+				j = len(sql) - i
+			} else {
+				j += 4
+			}
+			builder.WriteString(sql[i : i+j])
+			i += j
+		case isIdentifierStart(ch):
+			j := i + 1
+			for j < len(sql) && isIdentifierPart(sql[j]) {
+				j++
+			}
+			if i > 0 && (sql[i-1] == '@' || sql[i-1] == ':') {
+				// named parameter, its name has to match the named argument
+				builder.WriteString(sql[i:j])
+			} else {
+				builder.WriteString(c.Convert(sql[i:j]))
+			}
+			i = j
+		case ch >= '0' && ch <= '9':
+			// numbers keep their exponent and hex digits as written
+			j := i + 1
+			for j < len(sql) && (isIdentifierPart(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			builder.WriteString(sql[i:j])
+			i = j
+		default:
+			builder.WriteByte(ch)
+			i++
+		}
+	}
+
+	return builder.String()
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || c >= '0' && c <= '9' || c == '$' || c == '#'
+}
+
+// foldExpression folds the SQL written by hand in expr and in the conditions it's made of
+func (c IdentifierCase) foldExpression(expr clause.Expression) clause.Expression {
+	switch v := expr.(type) {
+	case clause.Expr:
+		v.SQL = c.foldSQL(v.SQL)
+		return v
+	case clause.NamedExpr:
+		v.SQL = c.foldSQL(v.SQL)
+		return v
+	case clause.AndConditions:
+		return clause.AndConditions{Exprs: c.foldExpressions(v.Exprs)}
+	case clause.OrConditions:
+		return clause.OrConditions{Exprs: c.foldExpressions(v.Exprs)}
+	case clause.NotConditions:
+		return clause.NotConditions{Exprs: c.foldExpressions(v.Exprs)}
+	}
+	return expr
+}
+
+func (c IdentifierCase) foldExpressions(exprs []clause.Expression) []clause.Expression {
+	folded := make([]clause.Expression, len(exprs))
+	for idx, expr := range exprs {
+		folded[idx] = c.foldExpression(expr)
+	}
+	return folded
+}
+
+// FoldRawSQL folds the identifiers of SQL given to Raw or Exec before it is executed
+func (d Dialector) FoldRawSQL(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SQL.Len() == 0 {
+		return
+	}
+
+	sql := d.IdentifierCase.foldSQL(db.Statement.SQL.String())
+	db.Statement.SQL.Reset()
+	db.Statement.SQL.WriteString(sql)
+}
@@ -0,0 +1,78 @@
+package gorm_dm8
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestFoldSQL(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"id=?", "ID=?"},
+		{"name LIKE 'abc%' and actived = ?", "NAME LIKE 'abc%' AND ACTIVED = ?"},
+		{`select "MixedCase", login_name from "users" u`, `SELECT "MixedCase", LOGIN_NAME FROM "users" U`},
+		{"title = 'it''s ok' or title = ?", "TITLE = 'it''s ok' OR TITLE = ?"},
+		{"count(*) -- keep this comment\nfrom t", "COUNT(*) -- keep this comment\nFROM T"},
+		{"a /* and b */ = 1e10", "A /* and b */ = 1e10"},
+		{"name = @name and age > 0x1f", "NAME = @name AND AGE > 0x1f"},
+		{"'unterminated", "'unterminated"},
+	}
+
+	for _, test := range tests {
+		if folded := UpperCase.foldSQL(test.sql); folded != test.expected {
+			t.Errorf("foldSQL(%q): expected %q, got %q", test.sql, test.expected, folded)
+		}
+	}
+}
+
+func TestFoldSQLCase(t *testing.T) {
+	sql := `select "MixedCase", Login_Name from users where title = 'Abc'`
+	for _, c := range []IdentifierCase{LowerCase, PreserveCase} {
+		if folded := c.foldSQL(sql); folded != sql {
+			t.Errorf("expected SQL to be kept as written with case %v, got %q", c, folded)
+		}
+	}
+}
+
+type Login struct {
+	ID        int
+	LoginName string `gorm:"size:100"`
+}
+
+// sentSQL returns the statements run on fake with their spaces collapsed
+func sentSQL(fake *fakeDB) []string {
+	statements := fake.statements()
+	for idx, statement := range statements {
+		statements[idx] = strings.Join(strings.Fields(statement), " ")
+	}
+	return statements
+}
+
+func TestFoldSentSQL(t *testing.T) {
+	fake := &fakeDB{}
+	db := fake.open(t, Config{IdentifierCase: LowerCase})
+
+	var accounts []Login
+	db.Where(&Login{LoginName: "a"}).Or(`"login_name" = ?`, "b").Order(clause.OrderByColumn{Column: clause.Column{Name: "ID"}}).Find(&accounts)
+	db.Raw(`SELECT "login_name" FROM "logins" WHERE "Id" = ?`, 1).Scan(&accounts)
+
+	expected := []string{
+		`SELECT * FROM "logins" WHERE "logins"."login_name" = ? OR "login_name" = ? ORDER BY "id"`,
+		`SELECT "login_name" FROM "logins" WHERE "Id" = ?`,
+	}
+	if statements := sentSQL(fake); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected %v, got %v", expected, statements)
+	}
+
+	fake = &fakeDB{}
+	db = fake.open(t, Config{})
+	db.Where("login_name = ? and 'Abc' = ?", "a", "Abc").Find(&accounts)
+	if expected := `SELECT * FROM "LOGINS" WHERE LOGIN_NAME = ? AND 'Abc' = ?`; sentSQL(fake)[0] != expected {
+		t.Errorf("expected %v, got %v", expected, sentSQL(fake))
+	}
+}
@@ -0,0 +1,360 @@
+package gorm_dm8
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// geometryTypes maps the data types of the geometry types to the DMGEO column types
+var geometryTypes = map[string]string{
+	"geometry":   "SYSGEO.ST_GEOMETRY",
+	"point":      "SYSGEO.ST_POINT",
+	"linestring": "SYSGEO.ST_LINESTRING",
+	"polygon":    "SYSGEO.ST_POLYGON",
+}
+
+// geometryTypeOf returns the DMGEO type of a geometry field, or "" when the field isn't one
+func geometryTypeOf(field *schema.Field) string {
+	return geometryTypes[strings.ToLower(string(field.DataType))]
+}
+
+// Coord is a coordinate of a LineString or a Polygon
+type Coord struct {
+	X, Y float64
+}
+
+// Point is a DMGEO ST_POINT, written with DMGEO.ST_GEOMFROMTEXT and scanned from WKT or WKB. The driver returns
+// geometry columns as objects, select them with clauses.STAsText to scan them. A nil *Point is written as NULL
+type Point struct {
+	X, Y float64
+	SRID int
+}
+
+// LineString is a DMGEO ST_LINESTRING, one without coordinates is written as LINESTRING EMPTY and a nil
+// *LineString as NULL
+type LineString struct {
+	Coords []Coord
+	SRID   int
+}
+
+// Polygon is a DMGEO ST_POLYGON, the first ring is the exterior, the others are holes. One without rings is written
+// as POLYGON EMPTY and a nil *Polygon as NULL
+type Polygon struct {
+	Rings [][]Coord
+	SRID  int
+}
+
+func (Point) GormDataType() string      { return "point" }
+func (LineString) GormDataType() string { return "linestring" }
+func (Polygon) GormDataType() string    { return "polygon" }
+
+func (p Point) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	return geometryValue(p.WKT(), p.SRID)
+}
+
+func (l LineString) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	return geometryValue(l.WKT(), l.SRID)
+}
+
+func (p Polygon) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	return geometryValue(p.WKT(), p.SRID)
+}
+
+func geometryValue(wkt string, srid int) clause.Expr {
+	return clause.Expr{SQL: "DMGEO.ST_GEOMFROMTEXT(?, ?)", Vars: []interface{}{wkt, srid}}
+}
+
+func (p Point) Value() (driver.Value, error)      { return p.WKT(), nil }
+func (l LineString) Value() (driver.Value, error) { return l.WKT(), nil }
+func (p Polygon) Value() (driver.Value, error)    { return p.WKT(), nil }
+
+// WKT formats the point as well-known text, e.g. POINT (1 2)
+func (p Point) WKT() string {
+	return "POINT (" + formatCoords([]Coord{{p.X, p.Y}}) + ")"
+}
+
+func (l LineString) WKT() string {
+	if len(l.Coords) == 0 {
+		return "LINESTRING EMPTY"
+	}
+	return "LINESTRING (" + formatCoords(l.Coords) + ")"
+}
+
+func (p Polygon) WKT() string {
+	if len(p.Rings) == 0 {
+		return "POLYGON EMPTY"
+	}
+	rings := make([]string, len(p.Rings))
+	for idx, ring := range p.Rings {
+		rings[idx] = "(" + formatCoords(ring) + ")"
+	}
+	return "POLYGON (" + strings.Join(rings, ", ") + ")"
+}
+
+// WKB encodes the point as little endian well-known binary
+func (p Point) WKB() []byte {
+	return newWKB(wkbPoint).coords([]Coord{{p.X, p.Y}}).Bytes()
+}
+
+func (l LineString) WKB() []byte {
+	return newWKB(wkbLineString).count(len(l.Coords)).coords(l.Coords).Bytes()
+}
+
+func (p Polygon) WKB() []byte {
+	w := newWKB(wkbPolygon).count(len(p.Rings))
+	for _, ring := range p.Rings {
+		w.count(len(ring)).coords(ring)
+	}
+	return w.Bytes()
+}
+
+func (p *Point) Scan(value interface{}) error {
+	return scanGeometry(value, wkbPoint, func(g geometry) {
+		*p = Point{SRID: g.srid}
+		if len(g.rings) > 0 && len(g.rings[0]) > 0 {
+			p.X, p.Y = g.rings[0][0].X, g.rings[0][0].Y
+		}
+	})
+}
+
+func (l *LineString) Scan(value interface{}) error {
+	return scanGeometry(value, wkbLineString, func(g geometry) {
+		*l = LineString{SRID: g.srid}
+		if len(g.rings) > 0 {
+			l.Coords = g.rings[0]
+		}
+	})
+}
+
+func (p *Polygon) Scan(value interface{}) error {
+	return scanGeometry(value, wkbPolygon, func(g geometry) {
+		*p = Polygon{Rings: g.rings, SRID: g.srid}
+	})
+}
+
+const (
+	wkbPoint      uint32 = 1
+	wkbLineString uint32 = 2
+	wkbPolygon    uint32 = 3
+	// ewkbSRID flags an extended WKB carrying its SRID
+	ewkbSRID uint32 = 0x20000000
+)
+
+var wktTypes = map[string]uint32{"POINT": wkbPoint, "LINESTRING": wkbLineString, "POLYGON": wkbPolygon}
+
+// geometry is a decoded point, linestring or polygon, a point and a linestring have a single ring
+type geometry struct {
+	typ   uint32
+	rings [][]Coord
+	srid  int
+}
+
+// scanGeometry decodes WKT, EWKT or WKB of the expected type, NULL leaves the zero geometry
+func scanGeometry(value interface{}, typ uint32, set func(geometry)) error {
+	var (
+		g   geometry
+		err error
+	)
+	switch v := value.(type) {
+	case nil:
+		set(g)
+		return nil
+	case string:
+		g, err = parseWKT(v)
+	case []byte:
+		if len(v) > 0 && (v[0] == 0 || v[0] == 1) {
+			g, err = parseWKB(v)
+		} else {
+			g, err = parseWKT(string(v))
+		}
+	default:
+		return fmt.Errorf("failed to scan %T into geometry, select the column with clauses.STAsText", value)
+	}
+	if err != nil {
+		return err
+	}
+	if g.typ != typ {
+		return fmt.Errorf("expected geometry type %d, got %d", typ, g.typ)
+	}
+	set(g)
+	return nil
+}
+
+func formatCoords(coords []Coord) string {
+	points := make([]string, len(coords))
+	for idx, c := range coords {
+		points[idx] = strconv.FormatFloat(c.X, 'f', -1, 64) + " " + strconv.FormatFloat(c.Y, 'f', -1, 64)
+	}
+	return strings.Join(points, ", ")
+}
+
+// parseWKT reads the well-known text of a point, a linestring or a polygon, optionally prefixed with SRID=n;, an
+// EMPTY geometry has no ring
+func parseWKT(s string) (g geometry, err error) {
+	text := strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToUpper(text), "SRID=") {
+		idx := strings.IndexByte(text, ';')
+		if idx < 0 {
+			return g, fmt.Errorf("invalid WKT %q", s)
+		}
+		if g.srid, err = strconv.Atoi(text[5:idx]); err != nil {
+			return g, fmt.Errorf("invalid WKT %q", s)
+		}
+		text = text[idx+1:]
+	}
+
+	if upper := strings.ToUpper(text); strings.HasSuffix(upper, "EMPTY") {
+		typ, ok := wktTypes[strings.TrimSpace(strings.TrimSuffix(upper, "EMPTY"))]
+		if !ok {
+			return g, fmt.Errorf("unsupported WKT %q", s)
+		}
+		g.typ = typ
+		return g, nil
+	}
+
+	start, end := strings.IndexByte(text, '('), strings.LastIndexByte(text, ')')
+	if start < 0 || end < start {
+		return g, fmt.Errorf("invalid WKT %q", s)
+	}
+	typ, ok := wktTypes[strings.ToUpper(strings.TrimSpace(text[:start]))]
+	if !ok {
+		return g, fmt.Errorf("unsupported WKT %q", s)
+	}
+	g.typ = typ
+
+	body := strings.TrimSpace(text[start+1 : end])
+	if typ != wkbPolygon {
+		coords, err := parseCoords(body)
+		if err != nil {
+			return g, fmt.Errorf("invalid WKT %q: %w", s, err)
+		}
+		g.rings = [][]Coord{coords}
+		return g, nil
+	}
+
+	for body != "" {
+		start, end := strings.IndexByte(body, '('), strings.IndexByte(body, ')')
+		if start != 0 || end < start {
+			return g, fmt.Errorf("invalid WKT %q", s)
+		}
+		coords, err := parseCoords(body[1:end])
+		if err != nil {
+			return g, fmt.Errorf("invalid WKT %q: %w", s, err)
+		}
+		g.rings = append(g.rings, coords)
+		body = strings.TrimLeft(body[end+1:], ", ")
+	}
+	return g, nil
+}
+
+func parseCoords(s string) ([]Coord, error) {
+	var coords []Coord
+	for _, point := range strings.Split(s, ",") {
+		fields := strings.Fields(point)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid coordinate %q", point)
+		}
+		x, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		coords = append(coords, Coord{x, y})
+	}
+	return coords, nil
+}
+
+// wkbWriter writes little endian well-known binary
+type wkbWriter struct {
+	bytes.Buffer
+}
+
+func newWKB(typ uint32) *wkbWriter {
+	w := &wkbWriter{}
+	w.WriteByte(1)
+	binary.Write(w, binary.LittleEndian, typ)
+	return w
+}
+
+func (w *wkbWriter) count(n int) *wkbWriter {
+	binary.Write(w, binary.LittleEndian, uint32(n))
+	return w
+}
+
+func (w *wkbWriter) coords(coords []Coord) *wkbWriter {
+	for _, c := range coords {
+		binary.Write(w, binary.LittleEndian, [2]float64{c.X, c.Y})
+	}
+	return w
+}
+
+// parseWKB reads the well-known binary of a point, a linestring or a polygon, extended WKB with SRID included
+func parseWKB(b []byte) (g geometry, err error) {
+	r := bytes.NewReader(b)
+	invalid := errors.New("invalid WKB")
+
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	if order, _ := r.ReadByte(); order == 0 {
+		byteOrder = binary.BigEndian
+	}
+	read := func(data interface{}) {
+		if err == nil && binary.Read(r, byteOrder, data) != nil {
+			err = invalid
+		}
+	}
+	readCoords := func(n uint32) []Coord {
+		if err != nil || uint64(n)*16 > uint64(r.Len()) {
+			err = invalid
+			return nil
+		}
+		coords := make([]Coord, n)
+		for idx := range coords {
+			var xy [2]float64
+			read(&xy)
+			coords[idx] = Coord{xy[0], xy[1]}
+		}
+		return coords
+	}
+
+	var typ uint32
+	read(&typ)
+	if typ&ewkbSRID != 0 {
+		var srid uint32
+		read(&srid)
+		g.srid = int(srid)
+	}
+	g.typ = typ &^ ewkbSRID
+
+	switch g.typ {
+	case wkbPoint:
+		g.rings = [][]Coord{readCoords(1)}
+	case wkbLineString:
+		var n uint32
+		read(&n)
+		g.rings = [][]Coord{readCoords(n)}
+	case wkbPolygon:
+		var rings uint32
+		read(&rings)
+		for i := uint32(0); i < rings && err == nil; i++ {
+			var n uint32
+			read(&n)
+			g.rings = append(g.rings, readCoords(n))
+		}
+	default:
+		return g, fmt.Errorf("unsupported WKB geometry type %d", g.typ)
+	}
+	return g, err
+}
@@ -0,0 +1,87 @@
+package gorm_dm8
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+type Place struct {
+	ID       int
+	Location Point
+	Route    LineString
+	Area     Polygon
+	Shape    string `gorm:"type:geometry"`
+}
+
+func TestGeometryDataType(t *testing.T) {
+	s, err := schema.Parse(&Place{}, &sync.Map{}, Namer{})
+	if err != nil {
+		t.Fatalf("failed to parse schema, got error %v", err)
+	}
+
+	dialector := Dialector{Config: &Config{}}
+	for name, expected := range map[string]string{
+		"Location": "SYSGEO.ST_POINT",
+		"Route":    "SYSGEO.ST_LINESTRING",
+		"Area":     "SYSGEO.ST_POLYGON",
+		"Shape":    "SYSGEO.ST_GEOMETRY",
+	} {
+		if dataType := dialector.DataTypeOf(s.LookUpField(name)); dataType != expected {
+			t.Errorf("expected data type %v for %v, got %v", expected, name, dataType)
+		}
+	}
+}
+
+func TestGeometryScan(t *testing.T) {
+	point := Point{X: 116.4, Y: -39.9}
+	route := LineString{Coords: []Coord{{0, 0}, {1, 1.5}, {2, 0}}}
+	area := Polygon{Rings: [][]Coord{{{0, 0}, {10, 0}, {10, 10}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}}
+
+	if wkt := area.WKT(); wkt != "POLYGON ((0 0, 10 0, 10 10, 0 0), (1 1, 2 1, 2 2, 1 1))" {
+		t.Errorf("unexpected WKT %v", wkt)
+	}
+
+	for _, value := range []interface{}{point.WKT(), point.WKB(), "SRID=4326;POINT(116.4 -39.9)"} {
+		var p Point
+		if err := p.Scan(value); err != nil || p.X != point.X || p.Y != point.Y {
+			t.Errorf("failed to scan %v into point, got %v, %v", value, p, err)
+		}
+	}
+	for _, value := range []interface{}{route.WKT(), route.WKB()} {
+		var l LineString
+		if err := l.Scan(value); err != nil || !reflect.DeepEqual(l, route) {
+			t.Errorf("failed to scan %v into linestring, got %v, %v", value, l, err)
+		}
+	}
+	for _, value := range []interface{}{area.WKT(), area.WKB()} {
+		var p Polygon
+		if err := p.Scan(value); err != nil || !reflect.DeepEqual(p, area) {
+			t.Errorf("failed to scan %v into polygon, got %v, %v", value, p, err)
+		}
+	}
+
+	for geometry, expected := range map[string]string{LineString{}.WKT(): "LINESTRING EMPTY", Polygon{}.WKT(): "POLYGON EMPTY"} {
+		if geometry != expected {
+			t.Errorf("expected %v, got %v", expected, geometry)
+		}
+	}
+	var empty Polygon
+	if err := empty.Scan("SRID=4326;POLYGON EMPTY"); err != nil || !reflect.DeepEqual(empty, Polygon{SRID: 4326}) {
+		t.Errorf("failed to scan an empty polygon, got %v, %v", empty, err)
+	}
+
+	var p Point
+	if err := p.Scan(struct{}{}); err == nil || !strings.Contains(err.Error(), "clauses.STAsText") {
+		t.Errorf("expected a driver object to be refused with a hint, got %v", err)
+	}
+	if err := p.Scan(route.WKT()); err == nil {
+		t.Errorf("expected a linestring not to scan into a point")
+	}
+	if err := p.Scan(point.WKB()[:10]); err == nil {
+		t.Errorf("expected truncated WKB to be refused")
+	}
+}
@@ -0,0 +1,158 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Interval is a time.Duration stored as INTERVAL DAY TO SECOND, time.Duration fields opt in by using this type.
+// Fractions of a second are kept to the microsecond, the precision of DM intervals
+type Interval time.Duration
+
+// ErrDurationInterval is returned by the migrator for a time.Duration field tagged type:interval, such a field binds
+// and scans nanoseconds, it needs the Interval type to be stored as INTERVAL
+var ErrDurationInterval = errors.New("time.Duration is stored as nanoseconds, use Interval for an INTERVAL column")
+
+// intervalDT is the *dm.DmIntervalDT the driver scans INTERVAL DAY TO SECOND into
+type intervalDT interface {
+	GetDTType() byte
+	// String formats the interval as INTERVAL 'd hh:mi:ss.ffffff' DAY(9) TO SECOND(6)
+	String() string
+}
+
+// isInterval reports whether field is an Interval or a pointer to one
+func isInterval(field *schema.Field) bool {
+	fieldType := field.FieldType
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType == reflect.TypeOf(Interval(0))
+}
+
+// checkIntervals refuses the time.Duration fields tagged type:interval
+func checkIntervals(fields ...*schema.Field) error {
+	for _, field := range fields {
+		if strings.EqualFold(string(field.DataType), "interval") && !isInterval(field) {
+			return fmt.Errorf("%w: %s.%s", ErrDurationInterval, field.Schema.Name, field.Name)
+		}
+	}
+	return nil
+}
+
+// intervalTypeOf returns the type of a field of type Interval, the precision tag sets the digits of the fraction of
+// a second
+func intervalTypeOf(field *schema.Field) string {
+	precision := 6
+	if field.Precision > 0 && field.Precision < precision {
+		precision = field.Precision
+	}
+	// 9 digits of days hold every time.Duration
+	return fmt.Sprintf("interval day(9) to second(%d)", precision)
+}
+
+func (i *Interval) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*i = 0
+		return nil
+	case int64:
+		*i = Interval(v)
+		return nil
+	case []byte:
+		return i.parse(string(v))
+	case string:
+		return i.parse(v)
+	case intervalDT:
+		return i.parse(v.String())
+	}
+	return fmt.Errorf("failed to scan %T into Interval", value)
+}
+
+// parse reads the DM text of a day to second interval, with or without the INTERVAL '...' DAY TO SECOND literal
+func (i *Interval) parse(s string) error {
+	text := strings.TrimSpace(s)
+	if start, end := strings.Index(text, "'"), strings.LastIndex(text, "'"); start >= 0 && end > start {
+		text = strings.TrimSpace(text[start+1 : end])
+	}
+
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimLeft(text, "+-")
+
+	var days, clock string
+	if idx := strings.IndexByte(text, ' '); idx >= 0 {
+		days, clock = text[:idx], strings.TrimSpace(text[idx+1:])
+	} else if strings.Contains(text, ":") {
+		clock = text
+	} else {
+		days = text
+	}
+
+	var d time.Duration
+	if days != "" {
+		n, err := strconv.ParseInt(days, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid interval %q", s)
+		}
+		d += time.Duration(n) * 24 * time.Hour
+	}
+	if clock != "" {
+		parts := strings.Split(clock, ":")
+		if len(parts) != 3 {
+			return fmt.Errorf("invalid interval %q", s)
+		}
+		for idx, unit := range []time.Duration{time.Hour, time.Minute} {
+			n, err := strconv.ParseInt(parts[idx], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid interval %q", s)
+			}
+			d += time.Duration(n) * unit
+		}
+		seconds, err := time.ParseDuration(parts[2] + "s")
+		if err != nil {
+			return fmt.Errorf("invalid interval %q", s)
+		}
+		d += seconds
+	}
+
+	if negative {
+		d = -d
+	}
+	*i = Interval(d)
+	return nil
+}
+
+// Value hands the interval to DM as text, which DM converts to INTERVAL DAY TO SECOND
+func (i Interval) Value() (driver.Value, error) {
+	return i.String(), nil
+}
+
+// String formats the interval as DM does, e.g. -1 02:03:04.500000
+func (i Interval) String() string {
+	d := time.Duration(i)
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	d = d.Truncate(time.Microsecond)
+
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+	return fmt.Sprintf("%s%d %02d:%02d:%02d.%06d", sign, days, hours, minutes, seconds, d/time.Microsecond)
+}
+
+func (Interval) GormDataType() string {
+	return "interval"
+}
@@ -0,0 +1,108 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+type Job struct {
+	ID      int
+	Timeout Interval
+	Backoff Interval `gorm:"precision:3"`
+	Retry   time.Duration
+	Grace   time.Duration `gorm:"type:interval"`
+}
+
+func TestIntervalDataType(t *testing.T) {
+	s, err := schema.Parse(&Job{}, &sync.Map{}, Namer{})
+	if err != nil {
+		t.Fatalf("failed to parse schema, got error %v", err)
+	}
+
+	dialector := Dialector{Config: &Config{}}
+	for name, expected := range map[string]string{
+		"Timeout": "interval day(9) to second(6)",
+		"Backoff": "interval day(9) to second(3)",
+		"Retry":   "bigint",
+	} {
+		if dataType := dialector.DataTypeOf(s.LookUpField(name)); dataType != expected {
+			t.Errorf("expected data type %v for %v, got %v", expected, name, dataType)
+		}
+	}
+}
+
+func TestIntervalValue(t *testing.T) {
+	for text, expected := range map[string]time.Duration{
+		"1 02:03:04.500000":                         26*time.Hour + 3*time.Minute + 4500*time.Millisecond,
+		"-0 00:00:01.000001":                        -(time.Second + time.Microsecond),
+		"+000000003 00:00:00.000000":                72 * time.Hour,
+		"INTERVAL '2 00:30:00' DAY(9) TO SECOND(6)": 48*time.Hour + 30*time.Minute,
+		"00:00:05": 5 * time.Second,
+	} {
+		var i Interval
+		if err := i.Scan(text); err != nil || time.Duration(i) != expected {
+			t.Errorf("expected %v scanned as %v, got %v, %v", text, expected, time.Duration(i), err)
+		}
+
+		value, _ := i.Value()
+		var roundTrip Interval
+		if err := roundTrip.Scan(value); err != nil || roundTrip != i {
+			t.Errorf("expected %v to survive a round trip, got %v, %v", value, time.Duration(roundTrip), err)
+		}
+	}
+
+	if value, _ := Interval(-(26*time.Hour + 1500*time.Millisecond)).Value(); value != "-1 02:00:01.500000" {
+		t.Errorf("expected -1 02:00:01.500000, got %v", value)
+	}
+
+	var i Interval
+	if err := i.Scan("1 02:03"); err == nil {
+		t.Errorf("expected invalid interval to be refused")
+	}
+}
+
+func TestIntervalDuration(t *testing.T) {
+	m := dryRun(t, Config{}).Migrator()
+	if err := m.CreateTable(&Job{}); !errors.Is(err, ErrDurationInterval) {
+		t.Errorf("expected a time.Duration tagged type:interval to be refused, got %v", err)
+	}
+	if err := m.AddColumn(&Job{}, "Grace"); !errors.Is(err, ErrDurationInterval) {
+		t.Errorf("expected a time.Duration tagged type:interval to be refused, got %v", err)
+	}
+	if err := m.AddColumn(&Job{}, "Timeout"); err != nil {
+		t.Errorf("expected Interval to be added, got %v", err)
+	}
+}
+
+// fakeInterval is the interval the driver scans, like *dm.DmIntervalDT
+type fakeInterval string
+
+func (fakeInterval) GetDTType() byte { return 2 }
+
+func (i fakeInterval) String() string { return string(i) }
+
+func TestIntervalDriverValue(t *testing.T) {
+	fake := &fakeDB{query: func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		return []string{"ID", "TIMEOUT"}, [][]driver.Value{{int64(1), fakeInterval("INTERVAL '-1 02:03:04.500000' DAY(9) TO SECOND(6)")}}, nil
+	}}
+	db := fake.openDriver(t, Config{})
+
+	var job struct {
+		ID      int
+		Timeout Interval
+	}
+	if err := db.Table("JOBS").Take(&job).Error; err != nil || time.Duration(job.Timeout) != -(26*time.Hour+3*time.Minute+4500*time.Millisecond) {
+		t.Errorf("expected the driver interval to be scanned, got %v, %v", time.Duration(job.Timeout), err)
+	}
+
+	var i Interval
+	if err := i.Scan(fmt.Stringer(time.Second)); err == nil {
+		t.Errorf("expected a value other than the driver interval to be refused")
+	}
+}
@@ -0,0 +1,83 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSON is a JSON document, DM stores it as CLOB, or as varchar when the field has a size. The migrator checks the
+// column with IS JSON through a constraint named like the ones of the check tag, e.g. CHK_SETTINGS_OPTIONS
+type JSON json.RawMessage
+
+// isJSON reports whether field is a JSON or a pointer to one
+func isJSON(field *schema.Field) bool {
+	fieldType := field.FieldType
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType == reflect.TypeOf(JSON(nil))
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	switch value.(type) {
+	case string, []byte, clobReader:
+		var c Clob
+		if err := c.Scan(value); err != nil {
+			return err
+		}
+		*j = JSON(c)
+		return nil
+	}
+	return fmt.Errorf("failed to scan %T into JSON", value)
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	if !json.Valid(j) {
+		return nil, errors.New("invalid JSON")
+	}
+	return string(j), nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[0:0], data...)
+	return nil
+}
+
+func (j JSON) String() string {
+	return string(j)
+}
+
+func (JSON) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType is the type of the column on DM, other databases get their json type
+func (JSON) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() != "dm" {
+		return ""
+	}
+	if field.Size > 0 && field.Size <= 8188 {
+		return fmt.Sprintf("varchar(%d)", field.Size)
+	}
+	return "clob"
+}
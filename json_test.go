@@ -0,0 +1,86 @@
+package gorm_dm8
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+type Setting struct {
+	ID      int
+	Options JSON
+	Labels  JSON `gorm:"size:1000;not null"`
+}
+
+func TestJSONDataType(t *testing.T) {
+	db := dryRun(t, Config{})
+	stmt := parseModel(t, db, &Setting{})
+
+	m := db.Migrator().(Migrator)
+	for name, expected := range map[string]string{
+		"Options": `clob`,
+		"Labels":  `varchar(1000) NOT NULL`,
+	} {
+		expr := m.FullDataTypeOf(stmt.Schema.LookUpField(name))
+		if sql := db.Dialector.Explain(expr.SQL, expr.Vars...); sql != expected {
+			t.Errorf("expected data type %v for %v, got %v", expected, name, sql)
+		}
+	}
+}
+
+func TestJSONCheck(t *testing.T) {
+	fake := &fakeDB{}
+	db := fake.open(t, Config{})
+	stmt := parseModel(t, db, &Setting{})
+	m := db.Migrator().(Migrator)
+
+	alters := func() (sqls []string) {
+		for _, sql := range fake.statements() {
+			if strings.HasPrefix(sql, "ALTER") {
+				sqls = append(sqls, sql)
+			}
+		}
+		fake.sqls = nil
+		return
+	}
+
+	if err := m.CreateTable(&Setting{}); err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+	sqls := alters()
+	for _, expected := range []string{
+		`ALTER TABLE "SETTINGS" ADD CONSTRAINT "CHK_SETTINGS_OPTIONS" CHECK ("OPTIONS" IS JSON)`,
+		`ALTER TABLE "SETTINGS" ADD CONSTRAINT "CHK_SETTINGS_LABELS" CHECK ("LABELS" IS JSON)`,
+	} {
+		if !strings.Contains(strings.Join(sqls, "\n"), expected) {
+			t.Errorf("expected %v, got %v", expected, sqls)
+		}
+	}
+
+	if m.typeChanged(stmt.Schema.LookUpField("Labels"), newColumnType("LABELS", "VARCHAR", 1000, sql.NullInt64{}, sql.NullInt64{})) {
+		t.Errorf("expected the type of a JSON column to be unchanged")
+	}
+	if err := m.MigrateColumn(&Setting{}, stmt.Schema.LookUpField("Options"), newColumnType("OPTIONS", "CLOB", 2147483647, sql.NullInt64{}, sql.NullInt64{})); err != nil {
+		t.Fatalf("failed to migrate column, got error %v", err)
+	}
+	expected := `ALTER TABLE "SETTINGS" ADD CONSTRAINT "CHK_SETTINGS_OPTIONS" CHECK ("OPTIONS" IS JSON)`
+	if sqls = alters(); len(sqls) != 1 || sqls[0] != expected {
+		t.Errorf("expected the missing check to be added, got %v", sqls)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	var j JSON
+	if err := j.Scan(`{"a":1}`); err != nil || j.String() != `{"a":1}` {
+		t.Errorf("failed to scan JSON, got %v, %v", j, err)
+	}
+	if err := j.Scan(nil); err != nil || j != nil {
+		t.Errorf("failed to scan NULL, got %v, %v", j, err)
+	}
+	if value, err := j.Value(); err != nil || value != nil {
+		t.Errorf("expected empty JSON to be NULL, got %v, %v", value, err)
+	}
+	if _, err := JSON(`{"a":`).Value(); err == nil {
+		t.Errorf("expected invalid JSON to be refused")
+	}
+}
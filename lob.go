@@ -0,0 +1,280 @@
+package gorm_dm8
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// lobChunkSize is the number of bytes or characters fetched from the server per read
+const lobChunkSize = 64 * 1024
+
+// ErrClobAsString is returned when a CLOB is opened on a connection reading CLOB as string, the driver has read the
+// whole CLOB already. Connections opened from Config.DSN stream CLOB, a Config.Conn needs clobAsString=false
+var ErrClobAsString = errors.New("CLOB is read as string, remove clobAsString=true from the DSN to stream it")
+
+// blobLocator reads and writes a *dm.DmBlob in place, positions start at 1
+type blobLocator interface {
+	GetLength() (int64, error)
+	ReadAt(pos int, dest []byte) (int, error)
+	Write(pos int, src []byte) (int, error)
+}
+
+// clobLocator reads and writes a *dm.DmClob in place, positions start at 1 and count characters
+type clobLocator interface {
+	clobReader
+	WriteString(pos int, s string) (int, error)
+}
+
+// OpenLobReader opens a reader over the BLOB or CLOB column of the row identified by the primary key of model. The
+// LOB is read chunk by chunk through the *dm.DmBlob or *dm.DmClob locator inside a transaction, which is ended by Close
+func OpenLobReader(db *gorm.DB, model interface{}, column string) (io.ReadCloser, error) {
+	tx, field, conds, err := beginLob(db, model, column)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := selectLob(tx, field, conds, false)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	r := &lobReader{tx: tx, pos: 1}
+	switch v := value.(type) {
+	case nil:
+	case string:
+		err = ErrClobAsString
+	case blobLocator:
+		r.length, err = v.GetLength()
+		r.read = func(pos int64) ([]byte, int64, error) {
+			chunk := make([]byte, min64(lobChunkSize, r.length-pos+1))
+			n, err := v.ReadAt(int(pos), chunk)
+			return chunk[:n], int64(n), err
+		}
+	case clobReader:
+		r.length, err = v.GetLength()
+		r.read = func(pos int64) ([]byte, int64, error) {
+			s, err := v.ReadString(int(pos), int(min64(lobChunkSize, r.length-pos+1)))
+			return []byte(s), int64(utf8.RuneCountInString(s)), err
+		}
+	default:
+		err = fmt.Errorf("column %s is not a LOB, got %T", field.DBName, value)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return r, nil
+}
+
+// OpenLobWriter opens a writer replacing the content of the BLOB or CLOB column of the row identified by the primary
+// key of model. The row stays locked until Close commits what was written, a failed write rolls everything back
+func OpenLobWriter(db *gorm.DB, model interface{}, column string) (io.WriteCloser, error) {
+	tx, field, conds, err := beginLob(db, model, column)
+	if err != nil {
+		return nil, err
+	}
+
+	empty := "EMPTY_CLOB()"
+	if field.DataType == schema.Bytes {
+		empty = "EMPTY_BLOB()"
+	}
+	// a NULL LOB has no locator to write through
+	result := tx.Table(field.Schema.Table).Clauses(clause.Where{Exprs: conds}).UpdateColumn(field.DBName, gorm.Expr(empty))
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = gorm.ErrRecordNotFound
+	}
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+
+	value, err := selectLob(tx, field, conds, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	w := &lobWriter{tx: tx, pos: 1}
+	switch v := value.(type) {
+	case blobLocator:
+		w.write = func(pos int64, p []byte) (int64, error) {
+			n, err := v.Write(int(pos), p)
+			return int64(n), err
+		}
+	case clobLocator:
+		w.clob = true
+		w.write = func(pos int64, p []byte) (int64, error) {
+			if _, err := v.WriteString(int(pos), string(p)); err != nil {
+				return 0, err
+			}
+			return int64(utf8.RuneCount(p)), nil
+		}
+	case string:
+		err = ErrClobAsString
+	default:
+		err = fmt.Errorf("column %s is not a LOB, got %T", field.DBName, value)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return w, nil
+}
+
+// beginLob starts the transaction a LOB is accessed in and finds the LOB field and the primary key conditions of model
+func beginLob(db *gorm.DB, model interface{}, column string) (tx *gorm.DB, field *schema.Field, conds []clause.Expression, err error) {
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(model); err != nil {
+		return nil, nil, nil, err
+	}
+	if field = stmt.Schema.LookUpField(column); field == nil {
+		field = stmt.Schema.LookUpField(db.NamingStrategy.ColumnName("", column))
+	}
+	if field == nil || field.DBName == "" {
+		return nil, nil, nil, fmt.Errorf("%w: %s", gorm.ErrInvalidField, column)
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(model))
+	if reflectValue.Kind() != reflect.Struct || len(stmt.Schema.PrimaryFields) == 0 {
+		return nil, nil, nil, gorm.ErrPrimaryKeyRequired
+	}
+	for _, primaryField := range stmt.Schema.PrimaryFields {
+		value, isZero := primaryField.ValueOf(db.Statement.Context, reflectValue)
+		if isZero {
+			return nil, nil, nil, gorm.ErrPrimaryKeyRequired
+		}
+		conds = append(conds, clause.Eq{Column: clause.Column{Name: primaryField.DBName}, Value: value})
+	}
+
+	// the CLOB locators are kept for the queries of the transaction instead of being read into strings
+	tx = db.Session(&gorm.Session{NewDB: true, Context: withLocators(db.Statement.Context)}).Begin()
+	return tx, field, conds, tx.Error
+}
+
+// selectLob fetches the LOB value as returned by the driver, a locator unless the driver materializes it
+func selectLob(tx *gorm.DB, field *schema.Field, conds []clause.Expression, forUpdate bool) (value interface{}, err error) {
+	query := tx.Table(field.Schema.Table).Select(field.DBName).Clauses(clause.Where{Exprs: conds})
+	if forUpdate {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	row := query.Row()
+	if query.Error != nil {
+		return nil, query.Error
+	}
+	if row == nil {
+		return nil, gorm.ErrDryRunModeUnsupported
+	}
+	err = row.Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = gorm.ErrRecordNotFound
+	}
+	return value, err
+}
+
+// lobReader buffers the chunks read from a LOB
+type lobReader struct {
+	tx     *gorm.DB
+	read   func(pos int64) (chunk []byte, n int64, err error)
+	pos    int64
+	length int64
+	buf    []byte
+}
+
+func (r *lobReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(r.buf) == 0 {
+		if r.read == nil || r.pos > r.length {
+			return 0, io.EOF
+		}
+		chunk, n, err := r.read(r.pos)
+		if err != nil && (err != io.EOF || n == 0) {
+			return 0, err
+		}
+		if n == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		r.buf, r.pos = chunk, r.pos+n
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close ends the transaction the LOB was read in
+func (r *lobReader) Close() error {
+	return r.tx.Rollback().Error
+}
+
+// lobWriter appends to a LOB, for a CLOB an incomplete UTF-8 sequence is held back until the rest of it is written
+type lobWriter struct {
+	tx      *gorm.DB
+	write   func(pos int64, p []byte) (n int64, err error)
+	clob    bool
+	pos     int64
+	pending []byte
+	err     error
+}
+
+func (w *lobWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	data := p
+	if w.clob {
+		data = append(w.pending, p...)
+		end := len(data)
+		for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(data[i]) {
+				if !utf8.FullRune(data[i:]) {
+					end = i
+				}
+				break
+			}
+		}
+		data, w.pending = data[:end], append([]byte(nil), data[end:]...)
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+
+	n, err := w.write(w.pos, data)
+	if err != nil {
+		w.err = err
+		w.tx.Rollback()
+		return 0, err
+	}
+	w.pos += n
+	return len(p), nil
+}
+
+// Close commits the written LOB
+func (w *lobWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = errors.New("LOB writer is closed")
+	if len(w.pending) > 0 {
+		w.tx.Rollback()
+		return errors.New("incomplete UTF-8 sequence at the end of the CLOB")
+	}
+	return w.tx.Commit().Error
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
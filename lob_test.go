@@ -0,0 +1,255 @@
+package gorm_dm8
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type Document struct {
+	ID      int
+	Content []byte
+	Body    string `gorm:"type:clob"`
+}
+
+// fakeBlob is a BLOB locator over data, length overrides the length it reports when set
+type fakeBlob struct {
+	data   []byte
+	length int64
+	reads  []int
+	err    error
+}
+
+func (b *fakeBlob) GetLength() (int64, error) {
+	if b.length > 0 {
+		return b.length, nil
+	}
+	return int64(len(b.data)), nil
+}
+
+func (b *fakeBlob) ReadAt(pos int, dest []byte) (int, error) {
+	b.reads = append(b.reads, len(dest))
+	if pos > len(b.data) {
+		return 0, nil
+	}
+	return copy(dest, b.data[pos-1:]), nil
+}
+
+func (b *fakeBlob) Write(pos int, src []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	b.data = append(b.data[:pos-1], src...)
+	return len(src), nil
+}
+
+// fakeClob is a CLOB locator, positions and lengths count characters
+type fakeClob struct {
+	runes []rune
+}
+
+func (c *fakeClob) GetLength() (int64, error) {
+	return int64(len(c.runes)), nil
+}
+
+func (c *fakeClob) ReadString(pos int, length int) (string, error) {
+	end := pos - 1 + length
+	if end > len(c.runes) {
+		end = len(c.runes)
+	}
+	return string(c.runes[pos-1 : end]), nil
+}
+
+func (c *fakeClob) WriteString(pos int, s string) (int, error) {
+	c.runes = append(c.runes[:pos-1], []rune(s)...)
+	return len([]rune(s)), nil
+}
+
+// lobDB opens a db whose queries return value as the single column of a single row
+func lobDB(t *testing.T, value driver.Value) (*gorm.DB, *fakeDB) {
+	fake := &fakeDB{query: func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		return []string{"LOB"}, [][]driver.Value{{value}}, nil
+	}}
+	return fake.open(t, Config{}), fake
+}
+
+// ended reports whether the last statement of fake is end, with no COMMIT when the transaction is rolled back
+func ended(fake *fakeDB, end string) bool {
+	statements := fake.statements()
+	if len(statements) == 0 || statements[0] != "BEGIN" || statements[len(statements)-1] != end {
+		return false
+	}
+	for _, statement := range statements {
+		if end == "ROLLBACK" && statement == "COMMIT" {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLobReaderChunks(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 15000)
+	blob := &fakeBlob{data: data}
+	db, fake := lobDB(t, blob)
+
+	r, err := OpenLobReader(db, &Document{ID: 1}, "Content")
+	if err != nil {
+		t.Fatalf("failed to open reader, got error %v", err)
+	}
+	result, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(result, data) {
+		t.Errorf("expected %v bytes, got %v bytes, error %v", len(data), len(result), err)
+	}
+	if expected := []int{lobChunkSize, lobChunkSize, len(data) - 2*lobChunkSize}; !reflect.DeepEqual(blob.reads, expected) {
+		t.Errorf("expected reads of %v bytes, got %v", expected, blob.reads)
+	}
+	if err = r.Close(); err != nil || !ended(fake, "ROLLBACK") {
+		t.Errorf("expected the read transaction to be rolled back, got %v, %v", fake.statements(), err)
+	}
+}
+
+func TestLobReaderClob(t *testing.T) {
+	text := strings.Repeat("达梦a", 30000)
+	db, _ := lobDB(t, &fakeClob{runes: []rune(text)})
+
+	r, err := OpenLobReader(db, &Document{ID: 1}, "body")
+	if err != nil {
+		t.Fatalf("failed to open reader, got error %v", err)
+	}
+	defer r.Close()
+	if result, err := io.ReadAll(r); err != nil || string(result) != text {
+		t.Errorf("expected %v bytes of text, got %v bytes, error %v", len(text), len(result), err)
+	}
+}
+
+func TestLobReaderEOF(t *testing.T) {
+	db, _ := lobDB(t, nil)
+	r, err := OpenLobReader(db, &Document{ID: 1}, "Content")
+	if err != nil {
+		t.Fatalf("failed to open reader, got error %v", err)
+	}
+	if result, err := io.ReadAll(r); err != nil || len(result) != 0 {
+		t.Errorf("expected NULL to read as empty, got %v, %v", result, err)
+	}
+	r.Close()
+
+	db, _ = lobDB(t, &fakeBlob{data: []byte("abc"), length: 10})
+	if r, err = OpenLobReader(db, &Document{ID: 1}, "Content"); err != nil {
+		t.Fatalf("failed to open reader, got error %v", err)
+	}
+	if _, err = io.ReadAll(r); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected unexpected EOF on a truncated LOB, got %v", err)
+	}
+	r.Close()
+}
+
+func TestLobReaderClobAsString(t *testing.T) {
+	db, fake := lobDB(t, "whole clob")
+	if _, err := OpenLobReader(db, &Document{ID: 1}, "Body"); !errors.Is(err, ErrClobAsString) {
+		t.Errorf("expected ErrClobAsString, got %v", err)
+	}
+	if !ended(fake, "ROLLBACK") {
+		t.Errorf("expected the transaction to be rolled back, got %v", fake.statements())
+	}
+}
+
+func TestLobWriterSplitRune(t *testing.T) {
+	clob := &fakeClob{}
+	db, fake := lobDB(t, clob)
+
+	w, err := OpenLobWriter(db, &Document{ID: 1}, "Body")
+	if err != nil {
+		t.Fatalf("failed to open writer, got error %v", err)
+	}
+	text := []byte("a达梦")
+	for _, chunk := range [][]byte{text[:2], text[2:5], text[5:]} {
+		if n, err := w.Write(chunk); err != nil || n != len(chunk) {
+			t.Fatalf("failed to write %v bytes, got %v, %v", len(chunk), n, err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("failed to close writer, got error %v", err)
+	}
+	if string(clob.runes) != string(text) {
+		t.Errorf("expected CLOB %v, got %v", string(text), string(clob.runes))
+	}
+
+	statements := fake.statements()
+	if !ended(fake, "COMMIT") || !strings.Contains(statements[1], "EMPTY_CLOB()") || !strings.Contains(statements[2], "FOR UPDATE") {
+		t.Errorf("expected the CLOB to be emptied, locked and committed, got %v", statements)
+	}
+}
+
+func TestLobWriterRollback(t *testing.T) {
+	clob := &fakeClob{}
+	db, fake := lobDB(t, clob)
+	w, err := OpenLobWriter(db, &Document{ID: 1}, "Body")
+	if err != nil {
+		t.Fatalf("failed to open writer, got error %v", err)
+	}
+	w.Write([]byte("达")[:2])
+	if err = w.Close(); err == nil || !ended(fake, "ROLLBACK") {
+		t.Errorf("expected an incomplete UTF-8 sequence to roll back, got %v, %v", fake.statements(), err)
+	}
+
+	failure := errors.New("write failed")
+	db, fake = lobDB(t, &fakeBlob{err: failure})
+	if w, err = OpenLobWriter(db, &Document{ID: 1}, "Content"); err != nil {
+		t.Fatalf("failed to open writer, got error %v", err)
+	}
+	if _, err = w.Write([]byte("abc")); !errors.Is(err, failure) {
+		t.Errorf("expected the write to fail, got %v", err)
+	}
+	if err = w.Close(); !errors.Is(err, failure) || !ended(fake, "ROLLBACK") {
+		t.Errorf("expected a failed write to roll back, got %v, %v", fake.statements(), err)
+	}
+}
+
+func TestLobWriterNotFound(t *testing.T) {
+	fake := &fakeDB{exec: func(string, []driver.NamedValue) (driver.Result, error) {
+		return driver.RowsAffected(0), nil
+	}}
+	if _, err := OpenLobWriter(fake.open(t, Config{}), &Document{ID: 1}, "Content"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+	if !ended(fake, "ROLLBACK") {
+		t.Errorf("expected the transaction to be rolled back, got %v", fake.statements())
+	}
+}
+
+func TestLobDefaultDSN(t *testing.T) {
+	text := strings.Repeat("达梦a", 30000)
+	clob := &fakeClob{runes: []rune(text)}
+	fake := &fakeDB{query: func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		return []string{"BODY"}, [][]driver.Value{{clob}}, nil
+	}}
+	db := fake.openDriver(t, Config{})
+
+	r, err := OpenLobReader(db, &Document{ID: 1}, "Body")
+	if err != nil {
+		t.Fatalf("failed to open reader with the default DSN, got error %v", err)
+	}
+	if result, err := io.ReadAll(r); err != nil || string(result) != text {
+		t.Errorf("expected %v bytes of text, got %v bytes, error %v", len(text), len(result), err)
+	}
+	r.Close()
+
+	w, err := OpenLobWriter(db, &Document{ID: 1}, "Body")
+	if err != nil {
+		t.Fatalf("failed to open writer with the default DSN, got error %v", err)
+	}
+	if _, err = w.Write([]byte("达梦")); err != nil || w.Close() != nil || string(clob.runes) != "达梦" {
+		t.Errorf("expected the CLOB to be written, got %v, %v", string(clob.runes), err)
+	}
+
+	var document Document
+	if err = db.First(&document, 1).Error; err != nil || document.Body != "达梦" {
+		t.Errorf("expected the CLOB to be read as string outside of the LOB API, got %q, %v", document.Body, err)
+	}
+}
@@ -0,0 +1,18 @@
+package gorm_dm8
+
+import (
+	"gorm.io/gorm"
+)
+
+// Merge builds the MERGE statement given by db.Clauses when Exec is called without SQL, e.g.
+//
+//	db.Clauses(clauses.MergeInto("LIVE").Using("STAGING", "s").On(...).WhenMatchedUpdate(...)).Exec("")
+func Merge(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+
+	if _, ok := db.Statement.Clauses["MERGE"]; ok {
+		db.Statement.Build("MERGE")
+	}
+}
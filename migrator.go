@@ -1,10 +1,17 @@
 package gorm_dm8
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Migrator struct {
@@ -20,10 +27,21 @@ func (m Migrator) CurrentDatabase() (name string) {
 func (m Migrator) AddColumn(value interface{}, field string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if field := stmt.Schema.LookUpField(field); field != nil {
-			return m.DB.Exec(
+			if err := checkIntervals(field); err != nil {
+				return err
+			}
+			if err := m.DB.Exec(
 				"ALTER TABLE ? ADD ? ?",
-				clause.Table{Name: stmt.Table}, clause.Column{Name: field.DBName}, m.DB.Migrator().FullDataTypeOf(field),
-			).Error
+				m.CurrentTable(stmt), clause.Column{Name: field.DBName}, m.DB.Migrator().FullDataTypeOf(field),
+			).Error; err != nil {
+				return err
+			}
+			for name, jsonField := range m.jsonChecks(stmt) {
+				if jsonField == field {
+					return m.DB.Migrator().CreateConstraint(value, name)
+				}
+			}
+			return nil
 		}
 		return fmt.Errorf("failed to look up field with name: %s", field)
 	})
@@ -39,18 +57,167 @@ func (m Migrator) DropColumn(value interface{}, name string) error {
 		).Error
 	})
 }
+
+// ForceAlterColumn is the setting letting AlterColumn narrow a column, which needs its data rewritten, e.g.
+// db.Set(gorm_dm8.ForceAlterColumn, true).AutoMigrate(&User{})
+const ForceAlterColumn = "dm:force_alter_column"
+
+// ErrNarrowingColumn is returned by AlterColumn for a change that could lose data unless ForceAlterColumn is set
+var ErrNarrowingColumn = errors.New("narrowing the column needs its data rewritten, set " + ForceAlterColumn + " to force it")
+
+// AlterColumn brings the column in line with the field. The type is changed with ALTER TABLE t MODIFY col type,
+// nullability, the default value and the comment are changed on their own when they are all that differs
 func (m Migrator) AlterColumn(value interface{}, field string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		if field := stmt.Schema.LookUpField(field); field != nil {
+		f := stmt.Schema.LookUpField(field)
+		if f == nil {
+			return fmt.Errorf("failed to look up field with name: %s", field)
+		}
+		if err := checkIntervals(f); err != nil {
+			return err
+		}
+		table, column := m.CurrentTable(stmt), clause.Column{Name: f.DBName}
+
+		columnTypes, err := m.DB.Migrator().ColumnTypes(value)
+		if err != nil {
+			return err
+		}
+		var columnType gorm.ColumnType
+		for _, c := range columnTypes {
+			if strings.EqualFold(c.Name(), f.DBName) {
+				columnType = c
+			}
+		}
+		if columnType == nil {
+			return m.DB.Exec("ALTER TABLE ? MODIFY ? ?", table, column, m.columnDefinition(f)).Error
+		}
+
+		if m.typeChanged(f, columnType) {
+			if reason := m.narrowing(f, columnType); reason != "" {
+				if force, _ := m.DB.Get(ForceAlterColumn); force != true {
+					return fmt.Errorf("%w: %s.%s %s", ErrNarrowingColumn, stmt.Table, f.DBName, reason)
+				}
+			}
+			if err = m.DB.Exec("ALTER TABLE ? MODIFY ? ?", table, column, gorm.Expr(m.Migrator.DataTypeOf(f))).Error; err != nil {
+				return err
+			}
+		}
+
+		if nullableChanged(f, columnType) {
+			nullability := "NULL"
+			if f.NotNull {
+				nullability = "NOT NULL"
+			}
+			if err = m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? SET "+nullability, table, column).Error; err != nil {
+				return err
+			}
+		}
+
+		if defaultChanged(f, columnType) {
+			if defaultValue := m.defaultValueOf(f); defaultValue != "" {
+				err = m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? SET DEFAULT ?", table, column, gorm.Expr(defaultValue)).Error
+			} else {
+				err = m.DB.Exec("ALTER TABLE ? ALTER COLUMN ? DROP DEFAULT", table, column).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if commentChanged(f, columnType) {
 			return m.DB.Exec(
-				"ALTER TABLE ? MODIFY COLUMN ? ?",
-				clause.Table{Name: stmt.Table}, clause.Column{Name: field.DBName}, m.FullDataTypeOf(field),
+				"COMMENT ON COLUMN ?.? IS ?", table, column, gorm.Expr(m.Migrator.Dialector.Explain("?", f.Comment)),
 			).Error
 		}
-		return fmt.Errorf("failed to look up field with name: %s", field)
+		return nil
 	})
 }
 
+// columnDefinition is the type, the default value and the nullability of the field
+func (m Migrator) columnDefinition(field *schema.Field) clause.Expr {
+	definition := m.Migrator.DataTypeOf(field)
+	if defaultValue := m.defaultValueOf(field); defaultValue != "" {
+		definition += " DEFAULT " + defaultValue
+	}
+	if field.NotNull {
+		definition += " NOT NULL"
+	}
+	return clause.Expr{SQL: definition}
+}
+
+// defaultValueOf is the default value of the field as SQL, or "" without one
+func (m Migrator) defaultValueOf(field *schema.Field) string {
+	switch {
+	case !field.HasDefaultValue || field.AutoIncrement || field.DefaultValue == "(-)":
+		return ""
+	case field.DefaultValueInterface != nil:
+		return m.Migrator.Dialector.Explain("?", field.DefaultValueInterface)
+	case strings.EqualFold(field.DefaultValue, "NULL"):
+		return ""
+	}
+	return field.DefaultValue
+}
+
+// integerRanks orders the integer types by their width
+var integerRanks = map[string]int{"BIT": 1, "TINYINT": 2, "SMALLINT": 3, "INT": 4, "BIGINT": 5}
+
+// widerTypes lists the types a column can be changed to without losing data, a CLOB or BLOB holds any string or bytes
+var widerTypes = map[string][]string{
+	"CHAR":      {"VARCHAR", "CLOB"},
+	"VARCHAR":   {"CLOB"},
+	"BINARY":    {"VARBINARY", "BLOB"},
+	"VARBINARY": {"BLOB"},
+	"TIMESTAMP": {"TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITH LOCAL TIME ZONE"},
+}
+
+// narrowing describes why changing the column to the type of the field could lose data, or returns ""
+func (m Migrator) narrowing(field *schema.Field, columnType gorm.ColumnType) string {
+	name, args := parseDataType(m.Migrator.DataTypeOf(field))
+	realName, _ := parseDataType(columnType.DatabaseTypeName())
+
+	if name != realName {
+		rank, ok := integerRanks[name]
+		realRank, realOK := integerRanks[realName]
+		switch {
+		case ok && realOK && rank > realRank:
+			return ""
+		case realOK && (name == "DECIMAL" || name == "DOUBLE"):
+			return ""
+		}
+		for _, wider := range widerTypes[realName] {
+			if wider == name {
+				return ""
+			}
+		}
+		return fmt.Sprintf("from %s to %s", realName, name)
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+	switch name {
+	case "CHAR", "VARCHAR", "BINARY", "VARBINARY":
+		if length, ok := columnType.Length(); ok && args[0] < length {
+			return fmt.Sprintf("from %s(%d) to %s(%d)", realName, length, name, args[0])
+		}
+	case "DECIMAL":
+		precision, scale, ok := columnType.DecimalSize()
+		newScale := int64(0)
+		if len(args) > 1 {
+			newScale = args[1]
+		}
+		// the digits before the point must not shrink, neither must the digits after it
+		if ok && (args[0]-newScale < precision-scale || newScale < scale) {
+			return fmt.Sprintf("from DECIMAL(%d,%d) to DECIMAL(%d,%d)", precision, scale, args[0], newScale)
+		}
+	default:
+		if precision, _, ok := columnType.DecimalSize(); ok && strings.HasPrefix(name, "TIMESTAMP") && args[0] < precision {
+			return fmt.Sprintf("from %s(%d) to %s(%d)", realName, precision, name, args[0])
+		}
+	}
+	return ""
+}
+
 func (m Migrator) RenameColumn(value interface{}, oldName, newName string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if field := stmt.Schema.LookUpField(oldName); field != nil {
@@ -69,11 +236,23 @@ func (m Migrator) RenameColumn(value interface{}, oldName, newName string) error
 }
 
 func (m Migrator) CreateTable(values ...interface{}) (err error) {
+	for _, value := range values {
+		if err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			return checkIntervals(stmt.Schema.Fields...)
+		}); err != nil {
+			return
+		}
+	}
 	if err = m.Migrator.CreateTable(values...); err != nil {
 		return
 	}
 	for _, value := range m.ReorderModels(values, false) {
 		if err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			for name := range m.jsonChecks(stmt) {
+				if err := m.DB.Migrator().CreateConstraint(value, name); err != nil {
+					return err
+				}
+			}
 			for _, field := range stmt.Schema.FieldsByDBName {
 				if field.Comment != "" {
 					if err := m.DB.Exec(
@@ -96,7 +275,8 @@ func (m Migrator) HasTable(value interface{}) bool {
 	var count int64
 
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		return m.DB.Raw("SELECT COUNT(*) FROM USER_TABLES WHERE TABLE_NAME = ?", stmt.Table).Row().Scan(&count)
+		owner, table := m.ownerOf(stmt)
+		return m.DB.Raw("SELECT COUNT(*) FROM ALL_TABLES WHERE OWNER = ? AND TABLE_NAME = ?", owner, table).Row().Scan(&count)
 	})
 
 	return count > 0
@@ -108,7 +288,7 @@ func (m Migrator) DropTable(values ...interface{}) error {
 	tx.Exec("SET FOREIGN_KEY_CHECKS = 0;")
 	for i := len(values) - 1; i >= 0; i-- {
 		if err := m.RunWithValue(values[i], func(stmt *gorm.Statement) error {
-			return tx.Exec("DROP TABLE IF EXISTS ? CASCADE", clause.Table{Name: stmt.Table}).Error
+			return tx.Exec("DROP TABLE IF EXISTS ? CASCADE", m.CurrentTable(stmt)).Error
 		}); err != nil {
 			return err
 		}
@@ -120,26 +300,52 @@ func (m Migrator) DropTable(values ...interface{}) error {
 func (m Migrator) HasConstraint(value interface{}, name string) bool {
 	var count int64
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		owner, table := m.ownerOf(stmt)
 		return m.DB.Raw(
-			"SELECT COUNT(*) FROM USER_CONSTRAINTS WHERE TABLE_NAME = ? AND CONSTRAINT_NAME = ?", stmt.Table, name,
+			"SELECT COUNT(*) FROM ALL_CONSTRAINTS WHERE OWNER = ? AND TABLE_NAME = ? AND CONSTRAINT_NAME = ?", owner, table, name,
 		).Row().Scan(&count)
 	}) == nil && count > 0
 }
 
+// jsonChecks maps the names of the IS JSON checks of the model to their JSON fields, a field with a check tag has
+// its own check instead
+func (m Migrator) jsonChecks(stmt *gorm.Statement) map[string]*schema.Field {
+	checks := map[string]*schema.Field{}
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && isJSON(field) && field.TagSettings["CHECK"] == "" {
+			checks[m.DB.NamingStrategy.CheckerName(stmt.Table, field.DBName)] = field
+		}
+	}
+	return checks
+}
+
+// CreateConstraint creates the IS JSON check of a JSON field as well as the constraints gorm knows of
+func (m Migrator) CreateConstraint(value interface{}, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if field := m.jsonChecks(stmt)[name]; field != nil {
+			return m.DB.Exec(
+				"ALTER TABLE ? ADD CONSTRAINT ? CHECK (? IS JSON)",
+				m.CurrentTable(stmt), clause.Column{Name: name}, clause.Column{Name: field.DBName},
+			).Error
+		}
+		return m.Migrator.CreateConstraint(value, name)
+	})
+}
+
 func (m Migrator) DropConstraint(value interface{}, name string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		for _, chk := range stmt.Schema.ParseCheckConstraints() {
 			if chk.Name == name {
 				return m.DB.Exec(
 					"ALTER TABLE ? DROP CHECK ?",
-					clause.Table{Name: stmt.Table}, clause.Column{Name: name},
+					m.CurrentTable(stmt), clause.Column{Name: name},
 				).Error
 			}
 		}
 
 		return m.DB.Exec(
 			"ALTER TABLE ? DROP CONSTRAINT ?",
-			clause.Table{Name: stmt.Table}, clause.Column{Name: name},
+			m.CurrentTable(stmt), clause.Column{Name: name},
 		).Error
 	})
 }
@@ -150,7 +356,11 @@ func (m Migrator) DropIndex(value interface{}, name string) error {
 			name = idx.Name
 		}
 
-		return m.DB.Exec("DROP INDEX ?", clause.Column{Name: name}, clause.Table{Name: stmt.Table}).Error
+		// the index belongs to the schema of its table
+		if schemaName, _ := splitTable(qualifiedTable(stmt)); schemaName != "" {
+			name = schemaName + "." + name
+		}
+		return m.DB.Exec("DROP INDEX ?", clause.Table{Name: name}).Error
 	})
 }
 
@@ -161,12 +371,544 @@ func (m Migrator) HasIndex(value interface{}, name string) bool {
 			name = idx.Name
 		}
 
+		owner, table := m.ownerOf(stmt)
+		return m.DB.Raw(
+			"SELECT COUNT(*) FROM ALL_INDEXES WHERE TABLE_OWNER = ? AND TABLE_NAME = ? AND INDEX_NAME = ?",
+			owner,
+			table,
+			name,
+		).Row().Scan(&count)
+	})
+
+	return count > 0
+}
+
+// currentSchema is the owner of the tables named without a schema
+var currentSchema = clause.Expr{SQL: "SYS_CONTEXT('userenv', 'current_schema')"}
+
+// splitTable splits a table name such as SCHEMA.TABLE into its schema and its name, the schema is empty without one
+func splitTable(name string) (schemaName, table string) {
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return "", name
+}
+
+// qualifiedTable returns the table of stmt with its schema, which gorm only keeps in the quoted TableExpr
+func qualifiedTable(stmt *gorm.Statement) string {
+	if stmt.TableExpr != nil && !strings.ContainsAny(stmt.TableExpr.SQL, " (") {
+		return strings.ReplaceAll(stmt.TableExpr.SQL, `"`, "")
+	}
+	return stmt.Table
+}
+
+// ownerOf returns the schema and the name of the table of stmt as the data dictionary lists them, folded to the
+// identifier case like quoted names
+func (m Migrator) ownerOf(stmt *gorm.Statement) (owner interface{}, table string) {
+	schemaName, table := splitTable(qualifiedTable(stmt))
+	if schemaName == "" {
+		return currentSchema, m.Dialector.IdentifierCase.Convert(table)
+	}
+	return m.Dialector.IdentifierCase.Convert(schemaName), m.Dialector.IdentifierCase.Convert(table)
+}
+
+// HasColumn checks ALL_TAB_COLUMNS for the column of the field, or for the column named name
+func (m Migrator) HasColumn(value interface{}, name string) bool {
+	var count int64
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if field := stmt.Schema.LookUpField(name); field != nil {
+			name = field.DBName
+		}
+
+		owner, table := m.ownerOf(stmt)
 		return m.DB.Raw(
-			"SELECT COUNT(*) FROM USER_INDEXES WHERE TABLE_NAME = ? AND INDEX_NAME = ?",
-			stmt.Table,
+			"SELECT COUNT(*) FROM ALL_TAB_COLUMNS WHERE OWNER = ? AND TABLE_NAME = ? AND COLUMN_NAME = ?",
+			owner,
+			table,
 			name,
 		).Row().Scan(&count)
 	})
 
 	return count > 0
 }
+
+// GetTables lists the tables of Config.Schema, or of the current schema
+func (m Migrator) GetTables() (tableList []string, err error) {
+	var owner interface{} = currentSchema
+	if m.Dialector.Schema != "" {
+		owner = m.Dialector.IdentifierCase.Convert(m.Dialector.Schema)
+	}
+	err = m.DB.Raw("SELECT TABLE_NAME FROM ALL_TABLES WHERE OWNER = ? ORDER BY TABLE_NAME", owner).Scan(&tableList).Error
+	return
+}
+
+// indexColumn is a column of an index as listed by ALL_IND_COLUMNS
+type indexColumn struct {
+	indexName, uniqueness, columnName string
+	primaryKey                        bool
+}
+
+// GetIndexes reads the indexes of the table from ALL_INDEXES and ALL_IND_COLUMNS, the index backing the primary
+// key is found through ALL_CONSTRAINTS
+func (m Migrator) GetIndexes(value interface{}) ([]gorm.Index, error) {
+	var indexes []gorm.Index
+	err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		owner, table := m.ownerOf(stmt)
+		rows, err := m.DB.Raw(
+			"SELECT I.INDEX_NAME, I.UNIQUENESS, C.COLUMN_NAME, "+
+				"(SELECT COUNT(*) FROM ALL_CONSTRAINTS K WHERE K.OWNER = I.TABLE_OWNER AND K.TABLE_NAME = I.TABLE_NAME "+
+				"AND K.INDEX_NAME = I.INDEX_NAME AND K.CONSTRAINT_TYPE = 'P') "+
+				"FROM ALL_INDEXES I JOIN ALL_IND_COLUMNS C ON C.INDEX_OWNER = I.OWNER AND C.INDEX_NAME = I.INDEX_NAME "+
+				"WHERE I.TABLE_OWNER = ? AND I.TABLE_NAME = ? ORDER BY I.INDEX_NAME, C.COLUMN_POSITION",
+			owner,
+			table,
+		).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var columns []indexColumn
+		for rows.Next() {
+			var column indexColumn
+			var primaryKeys int64
+			if err = rows.Scan(&column.indexName, &column.uniqueness, &column.columnName, &primaryKeys); err != nil {
+				return err
+			}
+			column.primaryKey = primaryKeys > 0
+			columns = append(columns, column)
+		}
+		indexes = groupIndexes(table, columns)
+		return rows.Err()
+	})
+	return indexes, err
+}
+
+// groupIndexes collects the columns listed in order of each index into a gorm.Index
+func groupIndexes(table string, columns []indexColumn) []gorm.Index {
+	var indexes []gorm.Index
+	byName := map[string]*migrator.Index{}
+	for _, column := range columns {
+		index, ok := byName[column.indexName]
+		if !ok {
+			index = &migrator.Index{
+				TableName:       table,
+				NameValue:       column.indexName,
+				PrimaryKeyValue: sql.NullBool{Bool: column.primaryKey, Valid: true},
+				UniqueValue:     sql.NullBool{Bool: column.uniqueness == "UNIQUE", Valid: true},
+			}
+			byName[column.indexName] = index
+			indexes = append(indexes, index)
+		}
+		index.ColumnList = append(index.ColumnList, column.columnName)
+	}
+	return indexes
+}
+
+// ColumnTypes reads the columns of the table from the data dictionary, ALL_TAB_COLUMNS for types, lengths,
+// nullability and defaults, ALL_COL_COMMENTS for comments, ALL_CONSTRAINTS for primary keys and single column
+// unique constraints and SYSCOLUMNS for identities
+func (m Migrator) ColumnTypes(value interface{}) ([]gorm.ColumnType, error) {
+	columnTypes := make([]gorm.ColumnType, 0)
+	err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		owner, table := m.ownerOf(stmt)
+		rows, err := m.DB.Raw(
+			"SELECT COLUMN_NAME, DATA_TYPE, DATA_LENGTH, DATA_PRECISION, DATA_SCALE, NULLABLE, DATA_DEFAULT "+
+				"FROM ALL_TAB_COLUMNS WHERE OWNER = ? AND TABLE_NAME = ? ORDER BY COLUMN_ID",
+			owner,
+			table,
+		).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		columns := map[string]*migrator.ColumnType{}
+		for rows.Next() {
+			var (
+				name, dataType, nullable string
+				length                   int64
+				precision, scale         sql.NullInt64
+				defaultValue             sql.NullString
+			)
+			if err = rows.Scan(&name, &dataType, &length, &precision, &scale, &nullable, &defaultValue); err != nil {
+				return err
+			}
+
+			columnType := newColumnType(name, dataType, length, precision, scale)
+			columnType.NullableValue.Bool = nullable == "Y"
+			if defaultValue.Valid {
+				columnType.DefaultValueValue = sql.NullString{String: strings.TrimSpace(defaultValue.String), Valid: true}
+			}
+			columns[name] = columnType
+			columnTypes = append(columnTypes, columnType)
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		return m.fillColumnTypes(owner, table, columns)
+	})
+	return columnTypes, err
+}
+
+// newColumnType fills the type of a column from its data dictionary entry, every value gorm would otherwise probe
+// with a query is set
+func newColumnType(name, dataType string, length int64, precision, scale sql.NullInt64) *migrator.ColumnType {
+	dataType = strings.ToUpper(dataType)
+	columnType := &migrator.ColumnType{
+		NameValue:          sql.NullString{String: name, Valid: true},
+		DataTypeValue:      sql.NullString{String: dataType, Valid: true},
+		ColumnTypeValue:    sql.NullString{String: dataType, Valid: true},
+		PrimaryKeyValue:    sql.NullBool{Valid: true},
+		UniqueValue:        sql.NullBool{Valid: true},
+		AutoIncrementValue: sql.NullBool{Valid: true},
+		LengthValue:        sql.NullInt64{Valid: true},
+		DecimalSizeValue:   sql.NullInt64{Valid: true},
+		ScaleValue:         sql.NullInt64{Valid: true},
+		NullableValue:      sql.NullBool{Bool: true, Valid: true},
+		CommentValue:       sql.NullString{Valid: true},
+		ScanTypeValue:      reflect.TypeOf(""),
+	}
+
+	switch dataType {
+	case "CHAR", "CHARACTER", "VARCHAR", "VARCHAR2", "NCHAR", "NVARCHAR", "BINARY", "VARBINARY", "RAW":
+		columnType.LengthValue.Int64 = length
+		columnType.ColumnTypeValue.String = fmt.Sprintf("%s(%d)", dataType, length)
+		if strings.Contains(dataType, "BINARY") || dataType == "RAW" {
+			columnType.ScanTypeValue = reflect.TypeOf([]byte{})
+		}
+	case "NUMERIC", "DECIMAL", "DEC", "NUMBER":
+		columnType.DecimalSizeValue.Int64, columnType.ScaleValue.Int64 = precision.Int64, scale.Int64
+		if precision.Valid && precision.Int64 > 0 {
+			columnType.ColumnTypeValue.String = fmt.Sprintf("%s(%d,%d)", dataType, precision.Int64, scale.Int64)
+		}
+		if scale.Int64 == 0 && precision.Valid {
+			columnType.ScanTypeValue = reflect.TypeOf(int64(0))
+		} else {
+			columnType.ScanTypeValue = reflect.TypeOf(float64(0))
+		}
+	case "BIT":
+		columnType.ScanTypeValue = reflect.TypeOf(false)
+	case "TINYINT", "BYTE", "SMALLINT", "INT", "INTEGER", "PLS_INTEGER", "BIGINT":
+		columnType.ScanTypeValue = reflect.TypeOf(int64(0))
+	case "REAL", "FLOAT", "DOUBLE", "DOUBLE PRECISION":
+		columnType.ScanTypeValue = reflect.TypeOf(float64(0))
+	case "BLOB", "IMAGE", "LONGVARBINARY", "BFILE":
+		columnType.ScanTypeValue = reflect.TypeOf([]byte{})
+	default:
+		if strings.HasPrefix(dataType, "DATE") || strings.HasPrefix(dataType, "TIME") {
+			columnType.ScanTypeValue = reflect.TypeOf(time.Time{})
+			if first, rest, _ := strings.Cut(dataType, " "); scale.Valid && (first == "TIMESTAMP" || first == "DATETIME") {
+				// the fraction digits of a timestamp are its scale
+				columnType.DecimalSizeValue.Int64 = scale.Int64
+				columnType.ColumnTypeValue.String = strings.TrimSpace(fmt.Sprintf("%s(%d) %s", first, scale.Int64, rest))
+			}
+		}
+	}
+	return columnType
+}
+
+// fillColumnTypes adds comments, keys and identities to the columns of table
+func (m Migrator) fillColumnTypes(owner interface{}, table string, columns map[string]*migrator.ColumnType) error {
+	rows, err := m.DB.Raw(
+		"SELECT COLUMN_NAME, COMMENTS FROM ALL_COL_COMMENTS WHERE OWNER = ? AND TABLE_NAME = ?", owner, table,
+	).Rows()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		var comment sql.NullString
+		if err = rows.Scan(&name, &comment); err != nil {
+			rows.Close()
+			return err
+		}
+		if columnType, ok := columns[name]; ok && comment.Valid {
+			columnType.CommentValue.String = comment.String
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	// a unique constraint makes its column unique only when it is the single column of the constraint
+	rows, err = m.DB.Raw(
+		"SELECT CC.COLUMN_NAME, C.CONSTRAINT_TYPE FROM ALL_CONSTRAINTS C "+
+			"JOIN ALL_CONS_COLUMNS CC ON CC.OWNER = C.OWNER AND CC.CONSTRAINT_NAME = C.CONSTRAINT_NAME "+
+			"WHERE C.OWNER = ? AND C.TABLE_NAME = ? AND (C.CONSTRAINT_TYPE = 'P' OR C.CONSTRAINT_TYPE = 'U' AND "+
+			"(SELECT COUNT(*) FROM ALL_CONS_COLUMNS UC WHERE UC.OWNER = C.OWNER AND UC.CONSTRAINT_NAME = C.CONSTRAINT_NAME) = 1)",
+		owner,
+		table,
+	).Rows()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name, constraintType string
+		if err = rows.Scan(&name, &constraintType); err != nil {
+			rows.Close()
+			return err
+		}
+		if columnType, ok := columns[name]; ok {
+			if constraintType == "P" {
+				columnType.PrimaryKeyValue.Bool = true
+			} else {
+				columnType.UniqueValue.Bool = true
+			}
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	// the first bit of SYSCOLUMNS.INFO2 flags an identity column, SYSOBJECTS names user tables SCHOBJ/UTAB, other
+	// objects such as views or indexes may have the name of the table
+	rows, err = m.DB.Raw(
+		"SELECT C.NAME FROM SYSCOLUMNS C JOIN SYSOBJECTS O ON O.ID = C.ID "+
+			"WHERE O.NAME = ? AND O.SCHID = SF_GET_SCHEMA_ID_BY_NAME(?) AND O.TYPE$ = 'SCHOBJ' AND O.SUBTYPE$ = 'UTAB' "+
+			"AND BITAND(C.INFO2, 1) = 1",
+		table,
+		owner,
+	).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return err
+		}
+		if columnType, ok := columns[name]; ok {
+			columnType.AutoIncrementValue.Bool = true
+		}
+	}
+	return rows.Err()
+}
+
+// typeAliases groups the names of the same DM type, the first name is the one DM reports in the data dictionary
+var typeAliases = [][]string{
+	{"TIMESTAMP", "DATETIME"},
+	{"INT", "INTEGER", "PLS_INTEGER"},
+	{"TINYINT", "BYTE"},
+	{"DECIMAL", "DEC", "NUMERIC", "NUMBER"},
+	{"VARCHAR", "VARCHAR2", "CHARACTER VARYING"},
+	{"CHAR", "CHARACTER"},
+	{"VARBINARY", "RAW"},
+	{"CLOB", "TEXT", "LONGVARCHAR"},
+	{"BLOB", "IMAGE", "LONGVARBINARY"},
+	{"DOUBLE", "FLOAT", "DOUBLE PRECISION"},
+	{"BIT", "BOOLEAN", "BOOL"},
+}
+
+// GetTypeAliases returns the other names of a DM type
+func (m Migrator) GetTypeAliases(databaseTypeName string) []string {
+	name := strings.ToUpper(databaseTypeName)
+	for _, aliases := range typeAliases {
+		for idx, alias := range aliases {
+			if alias == name {
+				return append(append([]string{}, aliases[:idx]...), aliases[idx+1:]...)
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalType returns the name DM reports for a type name
+func canonicalType(name string) string {
+	for _, aliases := range typeAliases {
+		for _, alias := range aliases {
+			if alias == name {
+				return aliases[0]
+			}
+		}
+	}
+	return name
+}
+
+// parseDataType splits a type as DataTypeOf emits it into the name DM reports and its size arguments, e.g.
+// timestamp(6) with time zone NULL is TIMESTAMP WITH TIME ZONE and 6
+func parseDataType(dataType string) (name string, args []int64) {
+	dataType = strings.ToUpper(strings.TrimSpace(dataType))
+	for _, clause := range []string{" IDENTITY", " NOT NULL", " NULL", " UNIQUE", " CHECK", " DEFAULT"} {
+		if idx := strings.Index(dataType, clause); idx >= 0 {
+			dataType = dataType[:idx]
+		}
+	}
+
+	var words []string
+	for dataType != "" {
+		start := strings.IndexByte(dataType, '(')
+		if start < 0 {
+			words = append(words, dataType)
+			break
+		}
+		words = append(words, dataType[:start])
+		end := strings.IndexByte(dataType[start:], ')')
+		if end < 0 {
+			break
+		}
+		if args == nil {
+			for _, arg := range strings.Split(dataType[start+1:start+end], ",") {
+				n, _ := strconv.ParseInt(strings.TrimSpace(arg), 10, 64)
+				args = append(args, n)
+			}
+		}
+		dataType = dataType[start+end+1:]
+	}
+
+	name = strings.Join(strings.Fields(strings.Join(words, " ")), " ")
+	name = strings.TrimPrefix(name, "SYSGEO.")
+	if first, rest, found := strings.Cut(name, " "); found {
+		return canonicalType(first) + " " + rest, args
+	}
+	return canonicalType(name), args
+}
+
+// MigrateColumn alters the column when it really differs from the field, a field which became unique gets a unique
+// constraint of its own
+func (m Migrator) MigrateColumn(value interface{}, field *schema.Field, columnType gorm.ColumnType) error {
+	if field.IgnoreMigration {
+		return nil
+	}
+	if err := checkIntervals(field); err != nil {
+		return err
+	}
+	if m.columnChanged(field, columnType) {
+		if err := m.DB.Migrator().AlterColumn(value, field.DBName); err != nil {
+			return err
+		}
+	}
+
+	if isJSON(field) {
+		if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			for name, jsonField := range m.jsonChecks(stmt) {
+				if jsonField == field && !m.DB.Migrator().HasConstraint(value, name) {
+					return m.DB.Migrator().CreateConstraint(value, name)
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	// a column which is no longer unique keeps its constraint, it may be named by DM
+	if unique, ok := columnType.Unique(); ok && !unique && field.Unique && !field.PrimaryKey {
+		return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			name := m.DB.NamingStrategy.IndexName(stmt.Table, field.DBName)
+			if idx := strings.IndexByte(name, '_'); idx >= 0 {
+				name = m.Dialector.IdentifierCase.Convert("uni") + name[idx:]
+			}
+			return m.DB.Exec(
+				"ALTER TABLE ? ADD CONSTRAINT ? UNIQUE (?)",
+				m.CurrentTable(stmt), clause.Column{Name: name}, clause.Column{Name: field.DBName},
+			).Error
+		})
+	}
+	return nil
+}
+
+// columnChanged compares the field with its column, uniqueness is migrated by MigrateColumn
+func (m Migrator) columnChanged(field *schema.Field, columnType gorm.ColumnType) bool {
+	if field.PrimaryKey {
+		return false
+	}
+
+	return m.typeChanged(field, columnType) ||
+		// like gorm, only a nullable column of a not null field is altered
+		nullableChanged(field, columnType) && field.NotNull ||
+		defaultChanged(field, columnType) ||
+		commentChanged(field, columnType)
+}
+
+// typeChanged compares the type DataTypeOf emits for the field with the one the data dictionary reports through the
+// DM type aliases
+func (m Migrator) typeChanged(field *schema.Field, columnType gorm.ColumnType) bool {
+	name, args := parseDataType(m.Migrator.DataTypeOf(field))
+	realName, _ := parseDataType(columnType.DatabaseTypeName())
+	if name != realName {
+		// unsized strings were VARCHAR(8188) before they became CLOB, such columns are kept
+		length, ok := columnType.Length()
+		return !(name == "CLOB" && realName == "VARCHAR" && ok && length == 8188 && field.TagSettings["TYPE"] == "")
+	}
+	if len(args) == 0 {
+		return false
+	}
+
+	switch name {
+	case "CHAR", "VARCHAR", "BINARY", "VARBINARY":
+		length, ok := columnType.Length()
+		return ok && length != args[0]
+	case "DECIMAL":
+		precision, scale, ok := columnType.DecimalSize()
+		return ok && (precision != args[0] || len(args) > 1 && scale != args[1])
+	}
+	if strings.HasPrefix(name, "TIMESTAMP") {
+		// the fraction digits of a timestamp
+		precision, _, ok := columnType.DecimalSize()
+		return ok && precision != args[0]
+	}
+	return false
+}
+
+// nullableChanged reports whether the column is nullable while the field is not null, or the other way round
+func nullableChanged(field *schema.Field, columnType gorm.ColumnType) bool {
+	nullable, ok := columnType.Nullable()
+	return ok && nullable == field.NotNull && !field.PrimaryKey
+}
+
+// defaultChanged reports whether the field and the column differ in having a default value or in its value
+func defaultChanged(field *schema.Field, columnType gorm.ColumnType) bool {
+	if field.PrimaryKey || field.AutoIncrement {
+		return false
+	}
+	currentDefaultNotNull := field.HasDefaultValue && field.DefaultValue != "(-)" && (field.DefaultValueInterface != nil || !strings.EqualFold(field.DefaultValue, "NULL"))
+	dv, dvNotNull := columnType.DefaultValue()
+	if dvNotNull && strings.EqualFold(dv, "NULL") {
+		dvNotNull = false
+	}
+	return dvNotNull != currentDefaultNotNull || dvNotNull && !sameDefaultValue(field, dv)
+}
+
+// commentChanged reports whether the comment of the column is not the one of the field
+func commentChanged(field *schema.Field, columnType gorm.ColumnType) bool {
+	comment, ok := columnType.Comment()
+	return ok && comment != field.Comment && !field.PrimaryKey
+}
+
+// sameDefaultValue compares the default value of the field with the one of the column, which DM reports as SQL,
+// e.g. 'abc' for abc and 1 for true
+func sameDefaultValue(field *schema.Field, dv string) bool {
+	expected := field.DefaultValue
+	if field.DefaultValueInterface != nil {
+		expected = fmt.Sprint(convertBool(field.DefaultValueInterface))
+	}
+	unquote := func(s string) string {
+		s = strings.TrimSpace(s)
+		for len(s) >= 2 && (s[0] == '(' && s[len(s)-1] == ')' || s[0] == '\'' && s[len(s)-1] == '\'') {
+			s = strings.TrimSpace(s[1 : len(s)-1])
+		}
+		return s
+	}
+	expected, dv = unquote(expected), unquote(dv)
+
+	switch field.DataType {
+	case schema.Int, schema.Uint, schema.Float, schema.Bool:
+		if x, err := strconv.ParseFloat(expected, 64); err == nil {
+			y, err := strconv.ParseFloat(dv, 64)
+			return err == nil && x == y
+		}
+	case schema.String:
+		if field.DefaultValueInterface != nil {
+			return expected == dv
+		}
+	}
+	// functions such as SYSDATE or NEWID()
+	return strings.EqualFold(strings.TrimSuffix(expected, "()"), strings.TrimSuffix(dv, "()"))
+}
@@ -0,0 +1,230 @@
+package gorm_dm8
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/migrator"
+)
+
+func TestNewColumnType(t *testing.T) {
+	null := sql.NullInt64{}
+	for _, c := range []struct {
+		dataType          string
+		length            int64
+		precision, scale  sql.NullInt64
+		columnType        string
+		expectedLength    int64
+		expectedPrecision int64
+		expectedScale     int64
+		scanType          reflect.Type
+	}{
+		{"VARCHAR", 100, null, null, "VARCHAR(100)", 100, 0, 0, reflect.TypeOf("")},
+		{"binary", 16, null, null, "BINARY(16)", 16, 0, 0, reflect.TypeOf([]byte{})},
+		{"DECIMAL", 17, sql.NullInt64{Int64: 10, Valid: true}, sql.NullInt64{Int64: 2, Valid: true}, "DECIMAL(10,2)", 0, 10, 2, reflect.TypeOf(float64(0))},
+		{"DECIMAL", 21, sql.NullInt64{Int64: 20, Valid: true}, sql.NullInt64{Valid: true}, "DECIMAL(20,0)", 0, 20, 0, reflect.TypeOf(int64(0))},
+		{"INT", 4, sql.NullInt64{Int64: 10, Valid: true}, sql.NullInt64{Valid: true}, "INT", 0, 0, 0, reflect.TypeOf(int64(0))},
+		{"BIT", 1, null, null, "BIT", 0, 0, 0, reflect.TypeOf(false)},
+		{"TIMESTAMP", 8, null, sql.NullInt64{Int64: 6, Valid: true}, "TIMESTAMP(6)", 0, 6, 0, reflect.TypeOf(time.Time{})},
+		{"TIMESTAMP WITH TIME ZONE", 10, null, sql.NullInt64{Int64: 3, Valid: true}, "TIMESTAMP(3) WITH TIME ZONE", 0, 3, 0, reflect.TypeOf(time.Time{})},
+		{"CLOB", 8188, null, null, "CLOB", 0, 0, 0, reflect.TypeOf("")},
+	} {
+		columnType := newColumnType("COL", c.dataType, c.length, c.precision, c.scale)
+		if result, _ := columnType.ColumnType(); result != c.columnType {
+			t.Errorf("expected column type %v for %v, got %v", c.columnType, c.dataType, result)
+		}
+		if length, ok := columnType.Length(); !ok || length != c.expectedLength {
+			t.Errorf("expected length %v for %v, got %v", c.expectedLength, c.dataType, length)
+		}
+		if precision, scale, ok := columnType.DecimalSize(); !ok || precision != c.expectedPrecision || scale != c.expectedScale {
+			t.Errorf("expected decimal size %v, %v for %v, got %v, %v", c.expectedPrecision, c.expectedScale, c.dataType, precision, scale)
+		}
+		if scanType := columnType.ScanType(); scanType != c.scanType {
+			t.Errorf("expected scan type %v for %v, got %v", c.scanType, c.dataType, scanType)
+		}
+		if _, ok := columnType.Nullable(); !ok {
+			t.Errorf("expected nullability to be known for %v", c.dataType)
+		}
+	}
+}
+
+type Article struct {
+	ID        int
+	Title     string `gorm:"size:100;not null"`
+	Slug      string `gorm:"size:100;unique"`
+	Views     int16
+	Price     float64 `gorm:"precision:10;scale:2"`
+	Published bool    `gorm:"default:true"`
+	Summary   string
+	Body      string    `gorm:"type:clob"`
+	CreatedAt time.Time `gorm:"precision:6"`
+}
+
+func TestMigrateColumnTypeAliases(t *testing.T) {
+	db := dryRun(t, Config{})
+	stmt := parseModel(t, db, &Article{})
+	m := db.Migrator().(Migrator)
+
+	valid := func(n int64) sql.NullInt64 { return sql.NullInt64{Int64: n, Valid: true} }
+	column := func(dataType string, length int64, precision, scale sql.NullInt64, change func(*migrator.ColumnType)) *migrator.ColumnType {
+		columnType := newColumnType("COL", dataType, length, precision, scale)
+		if change != nil {
+			change(columnType)
+		}
+		return columnType
+	}
+	notNull := func(c *migrator.ColumnType) { c.NullableValue.Bool = false }
+	defaultValue := func(dv string) func(*migrator.ColumnType) {
+		return func(c *migrator.ColumnType) { c.DefaultValueValue = sql.NullString{String: dv, Valid: true} }
+	}
+
+	for _, c := range []struct {
+		field      string
+		columnType *migrator.ColumnType
+		changed    bool
+	}{
+		{"Title", column("VARCHAR", 100, sql.NullInt64{}, sql.NullInt64{}, notNull), false},
+		{"Title", column("VARCHAR", 50, sql.NullInt64{}, sql.NullInt64{}, notNull), true},
+		{"Title", column("VARCHAR", 100, sql.NullInt64{}, sql.NullInt64{}, nil), true},
+		{"Views", column("INTEGER", 4, valid(10), valid(0), nil), false},
+		{"Views", column("BIGINT", 8, valid(19), valid(0), nil), true},
+		{"Price", column("NUMERIC", 9, valid(10), valid(2), nil), false},
+		{"Price", column("DECIMAL", 9, valid(12), valid(2), nil), true},
+		{"Published", column("BIT", 1, sql.NullInt64{}, sql.NullInt64{}, defaultValue("1")), false},
+		{"Published", column("BIT", 1, sql.NullInt64{}, sql.NullInt64{}, defaultValue("0")), true},
+		{"Published", column("BIT", 1, sql.NullInt64{}, sql.NullInt64{}, nil), true},
+		{"Summary", column("VARCHAR2", 8188, sql.NullInt64{}, sql.NullInt64{}, nil), false},
+		{"Summary", column("VARCHAR2", 100, sql.NullInt64{}, sql.NullInt64{}, nil), true},
+		{"Body", column("VARCHAR", 8188, sql.NullInt64{}, sql.NullInt64{}, nil), true},
+		{"Summary", column("CLOB", 2147483647, sql.NullInt64{}, sql.NullInt64{}, nil), false},
+		{"Body", column("TEXT", 2147483647, sql.NullInt64{}, sql.NullInt64{}, nil), false},
+		{"CreatedAt", column("TIMESTAMP", 8, sql.NullInt64{}, valid(6), nil), false},
+		{"CreatedAt", column("DATETIME", 8, sql.NullInt64{}, valid(3), nil), true},
+	} {
+		columnType, _ := c.columnType.ColumnType()
+		if changed := m.columnChanged(stmt.Schema.LookUpField(c.field), c.columnType); changed != c.changed {
+			t.Errorf("expected %v changed %v against %v, got %v", c.field, c.changed, columnType, changed)
+		}
+	}
+}
+
+func TestAlterColumnNarrowing(t *testing.T) {
+	db := dryRun(t, Config{})
+	stmt := parseModel(t, db, &Article{})
+	m := db.Migrator().(Migrator)
+
+	valid := func(n int64) sql.NullInt64 { return sql.NullInt64{Int64: n, Valid: true} }
+	for _, c := range []struct {
+		field      string
+		columnType *migrator.ColumnType
+		narrowing  bool
+	}{
+		{"Title", newColumnType("COL", "VARCHAR", 50, sql.NullInt64{}, sql.NullInt64{}), false},
+		{"Title", newColumnType("COL", "VARCHAR", 200, sql.NullInt64{}, sql.NullInt64{}), true},
+		{"Title", newColumnType("COL", "CHAR", 10, sql.NullInt64{}, sql.NullInt64{}), false},
+		{"Title", newColumnType("COL", "CLOB", 2147483647, sql.NullInt64{}, sql.NullInt64{}), true},
+		{"Views", newColumnType("COL", "TINYINT", 1, valid(3), valid(0)), false},
+		{"Views", newColumnType("COL", "BIGINT", 8, valid(19), valid(0)), true},
+		{"Price", newColumnType("COL", "DECIMAL", 9, valid(8), valid(2)), false},
+		{"Price", newColumnType("COL", "DECIMAL", 9, valid(12), valid(2)), true},
+		{"Price", newColumnType("COL", "DECIMAL", 9, valid(10), valid(4)), true},
+		{"Price", newColumnType("COL", "INT", 4, valid(10), valid(0)), false},
+		{"Summary", newColumnType("COL", "VARCHAR", 100, sql.NullInt64{}, sql.NullInt64{}), false},
+		{"Body", newColumnType("COL", "CHAR", 100, sql.NullInt64{}, sql.NullInt64{}), false},
+		{"CreatedAt", newColumnType("COL", "TIMESTAMP", 8, sql.NullInt64{}, valid(3)), false},
+		{"CreatedAt", newColumnType("COL", "TIMESTAMP", 8, sql.NullInt64{}, valid(9)), true},
+		{"CreatedAt", newColumnType("COL", "VARCHAR", 50, sql.NullInt64{}, sql.NullInt64{}), true},
+	} {
+		columnType, _ := c.columnType.ColumnType()
+		if reason := m.narrowing(stmt.Schema.LookUpField(c.field), c.columnType); (reason != "") != c.narrowing {
+			t.Errorf("expected %v narrowing %v against %v, got %q", c.field, c.narrowing, columnType, reason)
+		}
+	}
+}
+
+func TestGroupIndexes(t *testing.T) {
+	indexes := groupIndexes("USERS", []indexColumn{
+		{"IDX_USERS_NAME", "NONUNIQUE", "LAST_NAME", false},
+		{"IDX_USERS_NAME", "NONUNIQUE", "FIRST_NAME", false},
+		{"INDEX33555441", "UNIQUE", "ID", true},
+		{"UNI_USERS_LOGIN_NAME", "UNIQUE", "LOGIN_NAME", false},
+	})
+
+	for i, c := range []struct {
+		name       string
+		columns    []string
+		primaryKey bool
+		unique     bool
+	}{
+		{"IDX_USERS_NAME", []string{"LAST_NAME", "FIRST_NAME"}, false, false},
+		{"INDEX33555441", []string{"ID"}, true, true},
+		{"UNI_USERS_LOGIN_NAME", []string{"LOGIN_NAME"}, false, true},
+	} {
+		if i >= len(indexes) {
+			t.Fatalf("expected %v indexes, got %v", i+1, len(indexes))
+		}
+		index := indexes[i]
+		primaryKey, _ := index.PrimaryKey()
+		unique, _ := index.Unique()
+		if index.Table() != "USERS" || index.Name() != c.name || !reflect.DeepEqual(index.Columns(), c.columns) ||
+			primaryKey != c.primaryKey || unique != c.unique {
+			t.Errorf("expected index %v on %v, primary key %v, unique %v, got %v on %v, primary key %v, unique %v",
+				c.name, c.columns, c.primaryKey, c.unique, index.Name(), index.Columns(), primaryKey, unique)
+		}
+	}
+}
+
+func TestMigrateColumnUnique(t *testing.T) {
+	db, recorder := record(dryRun(t, Config{}))
+	stmt := parseModel(t, db, &Article{})
+	m := db.Migrator().(Migrator)
+
+	field := stmt.Schema.LookUpField("Slug")
+	unique := newColumnType("SLUG", "VARCHAR", 100, sql.NullInt64{}, sql.NullInt64{})
+	unique.UniqueValue.Bool = true
+	if m.columnChanged(field, unique) || m.columnChanged(field, newColumnType("SLUG", "VARCHAR", 100, sql.NullInt64{}, sql.NullInt64{})) {
+		t.Errorf("expected uniqueness not to alter the column")
+	}
+
+	if err := m.MigrateColumn(&Article{}, field, unique); err != nil || len(recorder.sqls) != 0 {
+		t.Errorf("expected no statement for a unique column, got %v, %v", recorder.sqls, err)
+	}
+	if err := m.MigrateColumn(&Article{}, field, newColumnType("SLUG", "VARCHAR", 100, sql.NullInt64{}, sql.NullInt64{})); err != nil {
+		t.Fatalf("failed to migrate column, got error %v", err)
+	}
+	expected := `ALTER TABLE "ARTICLES" ADD CONSTRAINT "UNI_ARTICLES_SLUG" UNIQUE ("SLUG")`
+	if len(recorder.sqls) != 1 || recorder.sqls[0] != expected {
+		t.Errorf("expected %v, got %v", expected, recorder.sqls)
+	}
+}
+
+func TestColumnTypesRowsErr(t *testing.T) {
+	for _, failing := range []string{"ALL_COL_COMMENTS", "ALL_CONSTRAINTS", "SYSCOLUMNS"} {
+		broken := errors.New("connection lost")
+		fake := &fakeDB{query: func(query string, _ []driver.NamedValue) ([]string, [][]driver.Value, error) {
+			switch {
+			case strings.Contains(query, failing):
+				return []string{"NAME"}, [][]driver.Value{{broken}}, nil
+			case strings.Contains(query, "ALL_TAB_COLUMNS"):
+				return []string{"COLUMN_NAME", "DATA_TYPE", "DATA_LENGTH", "DATA_PRECISION", "DATA_SCALE", "NULLABLE", "DATA_DEFAULT"},
+					[][]driver.Value{{"ID", "INT", int64(4), int64(10), int64(0), "N", nil}}, nil
+			}
+			return nil, nil, nil
+		}}
+		if _, err := fake.open(t, Config{}).Migrator().ColumnTypes(&Article{}); !errors.Is(err, broken) {
+			t.Errorf("expected the error reading %v to be returned, got %v", failing, err)
+		}
+		if failing == "SYSCOLUMNS" {
+			for _, statement := range fake.statements() {
+				if strings.Contains(statement, "SYSOBJECTS") && !strings.Contains(statement, "O.SUBTYPE$ = 'UTAB'") {
+					t.Errorf("expected the identity lookup to be limited to tables, got %v", statement)
+				}
+			}
+		}
+	}
+}
@@ -1,38 +1,147 @@
 package gorm_dm8
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"gorm.io/gorm/schema"
 	"strings"
+	"unicode/utf8"
 )
 
+// MaxIdentifierLength is the longest name in bytes DM accepts for objects
+const MaxIdentifierLength = 128
+
+// ReservedWords are the DM reserved words, table and column names colliding with them get a trailing underscore when
+// Namer.EscapeReservedWords is set
+var ReservedWords = map[string]bool{
+	"ADD": true, "ALL": true, "ALTER": true, "AND": true, "ANY": true, "ARRAY": true, "AS": true, "ASC": true,
+	"AUDIT": true, "BEGIN": true, "BETWEEN": true, "BOTH": true, "BY": true, "CALL": true, "CASE": true,
+	"CAST": true, "CHECK": true, "CLUSTER": true, "COLUMN": true, "COMMENT": true, "COMMIT": true,
+	"CONNECT": true, "CONNECT_BY_ROOT": true, "CONSTRAINT": true, "CONTEXT": true, "CREATE": true, "CROSS": true,
+	"CURRENT": true, "CURSOR": true, "DECLARE": true, "DEFAULT": true, "DELETE": true, "DESC": true,
+	"DISTINCT": true, "DROP": true, "ELSE": true, "END": true, "EXCEPT": true, "EXCEPTION": true, "EXEC": true,
+	"EXECUTE": true, "EXISTS": true, "EXIT": true, "FETCH": true, "FOR": true, "FOREIGN": true, "FROM": true,
+	"FULL": true, "FUNCTION": true, "GOTO": true, "GRANT": true, "GROUP": true, "HAVING": true, "IDENTITY": true,
+	"IF": true, "IN": true, "INDEX": true, "INNER": true, "INSERT": true, "INTERSECT": true, "INTERVAL": true,
+	"INTO": true, "IS": true, "JOIN": true, "LEFT": true, "LEVEL": true, "LIKE": true, "LIMIT": true,
+	"LOOP": true, "MERGE": true, "MINUS": true, "NATURAL": true, "NEW": true, "NOCYCLE": true, "NOT": true,
+	"NULL": true, "OF": true, "OFFSET": true, "ON": true, "OPTION": true, "OR": true, "ORDER": true,
+	"OUTER": true, "OVERLAPS": true, "PRIMARY": true, "PRIOR": true, "PRIVILEGES": true, "PROCEDURE": true,
+	"PUBLIC": true, "RAISE": true, "REFERENCES": true, "RETURN": true, "RETURNING": true, "REVOKE": true,
+	"RIGHT": true, "ROLLBACK": true, "ROW": true, "ROWID": true, "ROWNUM": true, "ROWS": true,
+	"SAVEPOINT": true, "SELECT": true, "SET": true, "SOME": true, "START": true, "SYNONYM": true,
+	"SYSDATE": true, "TABLE": true, "THEN": true, "TO": true, "TOP": true, "TRIGGER": true, "TRUNCATE": true,
+	"UNION": true, "UNIQUE": true, "UPDATE": true, "USER": true, "USING": true, "VALUES": true, "VIEW": true,
+	"WHEN": true, "WHERE": true, "WHILE": true, "WITH": true,
+}
+
+// IdentifierCase selects how names of tables, columns and other objects are folded before they are sent to DM
+type IdentifierCase int
+
+const (
+	// UpperCase folds names to upper case, as DM does with unquoted names
+	UpperCase IdentifierCase = iota
+	// PreserveCase keeps names as they are, for quoted mixed case objects, SQL written by hand has to quote them
+	PreserveCase
+	// LowerCase folds names to lower case, for schemas created with CASE_SENSITIVE=Y and lower case objects, SQL
+	// written by hand has to quote them
+	LowerCase
+)
+
+// Convert folds name to the case
+func (c IdentifierCase) Convert(name string) string {
+	switch c {
+	case PreserveCase:
+		return name
+	case LowerCase:
+		return strings.ToLower(name)
+	default:
+		return strings.ToUpper(name)
+	}
+}
+
+// Namer folds the names given by the wrapped naming strategy to the identifier case, the strategy configured with
+// gorm.Config.NamingStrategy is wrapped by Dialector.Initialize
 type Namer struct {
 	schema.NamingStrategy
+	// Strategy is wrapped in place of NamingStrategy when set, for naming strategies of other types
+	Strategy schema.Namer
+	Case     IdentifierCase
+	// Schema qualifies the table names when set, names returned by TableName() are used as they are
+	Schema string
+	// EscapeReservedWords renames tables and columns named after ReservedWords, quoted names need no renaming, so
+	// this only suits names which are also written unquoted in hand written SQL
+	EscapeReservedWords bool
 }
 
 func ConvertNameToFormat(x string) string {
-	return strings.ToUpper(x)
+	return UpperCase.Convert(x)
+}
+
+func (n Namer) namer() schema.Namer {
+	if n.Strategy == nil {
+		return n.NamingStrategy
+	}
+	return n.Strategy
+}
+
+// format folds name to the identifier case and shortens it to MaxIdentifierLength, a hash of the whole name
+// keeps shortened names unique
+func (n Namer) format(name string) string {
+	name = n.Case.Convert(name)
+	if len(name) <= MaxIdentifierLength {
+		return name
+	}
+
+	hash := sha1.Sum([]byte(name))
+	suffix := n.Case.Convert("_" + hex.EncodeToString(hash[:])[:8])
+	end := MaxIdentifierLength - len(suffix)
+	for end > 0 && !utf8.RuneStart(name[end]) {
+		end--
+	}
+	return name[:end] + suffix
+}
+
+// escape renames table and column names which are DM reserved words when EscapeReservedWords is set
+func (n Namer) escape(name string) string {
+	if n.EscapeReservedWords && ReservedWords[strings.ToUpper(name)] {
+		return name + "_"
+	}
+	return name
+}
+
+// qualify prefixes the table name with the schema
+func (n Namer) qualify(name string) string {
+	if n.Schema == "" {
+		return name
+	}
+	return n.Case.Convert(n.Schema) + "." + name
 }
 
 func (n Namer) TableName(table string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.TableName(table))
+	return n.qualify(n.format(n.escape(n.namer().TableName(table))))
+}
+
+func (n Namer) SchemaName(table string) string {
+	return n.namer().SchemaName(table)
 }
 
 func (n Namer) ColumnName(table, column string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.ColumnName(table, column))
+	return n.format(n.escape(n.namer().ColumnName(table, column)))
 }
 
 func (n Namer) JoinTableName(table string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.JoinTableName(table))
+	return n.qualify(n.format(n.escape(n.namer().JoinTableName(table))))
 }
 
 func (n Namer) RelationshipFKName(relationship schema.Relationship) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.RelationshipFKName(relationship))
+	return n.format(n.namer().RelationshipFKName(relationship))
 }
 
 func (n Namer) CheckerName(table, column string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.CheckerName(table, column))
+	return n.format(n.namer().CheckerName(table, column))
 }
 
 func (n Namer) IndexName(table, column string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.IndexName(table, column))
+	return n.format(n.namer().IndexName(table, column))
 }
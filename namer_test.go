@@ -0,0 +1,139 @@
+package gorm_dm8
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestNamerWrapsNamingStrategy(t *testing.T) {
+	namer := Namer{NamingStrategy: schema.NamingStrategy{
+		TablePrefix:   "t_",
+		SingularTable: true,
+		NameReplacer:  strings.NewReplacer("CID", "Cid"),
+	}}
+
+	if name := namer.TableName("UserCID"); name != "T_USER_CID" {
+		t.Errorf("expected table name T_USER_CID, got %v", name)
+	}
+	if name := namer.ColumnName("", "LoginName"); name != "LOGIN_NAME" {
+		t.Errorf("expected column name LOGIN_NAME, got %v", name)
+	}
+
+	namer.Case = PreserveCase
+	if name := namer.TableName("User"); name != "t_user" {
+		t.Errorf("expected table name t_user, got %v", name)
+	}
+}
+
+func TestNamerInitialize(t *testing.T) {
+	for strategy, expected := range map[schema.Namer]string{
+		schema.NamingStrategy{TablePrefix: "t_"}: "T_INVOICES",
+		CustomNamer{}:                            "INVOICES",
+	} {
+		db, err := gorm.Open(New(Config{Conn: &sql.DB{}}), &gorm.Config{NamingStrategy: strategy, DryRun: true, DisableAutomaticPing: true})
+		if err != nil {
+			t.Fatalf("failed to open dry run db: %v", err)
+		}
+		namer := db.NamingStrategy.(Namer)
+		if name := namer.TableName("Invoice"); name != expected {
+			t.Errorf("expected table name %v, got %v", expected, name)
+		}
+		if _, custom := strategy.(CustomNamer); custom != (namer.Strategy != nil) {
+			t.Errorf("expected only strategies other than schema.NamingStrategy to be wrapped as Strategy, got %+v", namer)
+		}
+	}
+
+	db := dryRun(t, Config{})
+	if prefix := db.NamingStrategy.(Namer).NamingStrategy.TablePrefix; prefix != "" {
+		t.Errorf("expected the default NamingStrategy, got prefix %v", prefix)
+	}
+}
+
+func TestNamerReservedWords(t *testing.T) {
+	for column, expected := range map[string]string{"Comment": "COMMENT", "Level": "LEVEL", "Order": "ORDER"} {
+		if name := (Namer{}).ColumnName("", column); name != expected {
+			t.Errorf("expected column name %v for %v without escaping, got %v", expected, column, name)
+		}
+	}
+
+	namer := Namer{EscapeReservedWords: true}
+	for column, expected := range map[string]string{"Comment": "COMMENT_", "Level": "LEVEL_", "RowID": "ROW_ID", "Rowid": "ROWID_", "Title": "TITLE"} {
+		if name := namer.ColumnName("", column); name != expected {
+			t.Errorf("expected column name %v for %v, got %v", expected, column, name)
+		}
+	}
+}
+
+func TestNamerLongNames(t *testing.T) {
+	namer := Namer{Strategy: CustomNamer{}}
+	long := strings.Repeat("a", 150)
+
+	name := namer.IndexName(long, "b")
+	if len(name) != MaxIdentifierLength {
+		t.Errorf("expected index name of %v bytes, got %v", MaxIdentifierLength, len(name))
+	}
+	if name != namer.IndexName(long, "b") {
+		t.Errorf("expected shortened index names to be stable")
+	}
+	if name == namer.IndexName(long, "c") {
+		t.Errorf("expected shortened index names to be unique")
+	}
+	if !strings.HasPrefix(name, "IDX_AAA") {
+		t.Errorf("expected shortened index name to keep its prefix, got %v", name)
+	}
+}
+
+// CustomNamer names indexes without shortening them
+type CustomNamer struct {
+	schema.NamingStrategy
+}
+
+func (CustomNamer) IndexName(table, column string) string {
+	return "idx_" + table + "_" + column
+}
+
+type Invoice struct {
+	ID     int
+	Amount float64
+}
+
+type LedgerEntry struct {
+	ID int
+}
+
+func (LedgerEntry) TableName() string {
+	return "finance.ledger_entries"
+}
+
+func TestNamerSchema(t *testing.T) {
+	db := dryRun(t, Config{Schema: "sales"})
+
+	for model, expected := range map[interface{}]string{
+		&Invoice{}:     `SELECT * FROM "SALES"."INVOICES"`,
+		&LedgerEntry{}: `SELECT * FROM "FINANCE"."LEDGER_ENTRIES"`,
+	} {
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.Find(model) })
+		if strings.Join(strings.Fields(sql), " ") != expected {
+			t.Errorf("expected %v, got %v", expected, sql)
+		}
+	}
+
+	m := db.Migrator().(Migrator)
+	for model, expected := range map[interface{}][]interface{}{
+		&Invoice{}:     {"SALES", "INVOICES"},
+		&LedgerEntry{}: {"FINANCE", "LEDGER_ENTRIES"},
+		"users":        {currentSchema, "USERS"},
+	} {
+		m.RunWithValue(model, func(stmt *gorm.Statement) error {
+			if owner, table := m.ownerOf(stmt); !reflect.DeepEqual(owner, expected[0]) || table != expected[1] {
+				t.Errorf("expected table %v of %v, got %v of %v", expected[1], expected[0], table, owner)
+			}
+			return nil
+		})
+	}
+}
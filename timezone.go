@@ -0,0 +1,136 @@
+package gorm_dm8
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// TimeType is the column type of time.Time fields
+type TimeType string
+
+const (
+	// DateTime keeps neither the time zone nor the offset, the default
+	DateTime TimeType = "datetime"
+	// Timestamp is the same as DateTime with its standard name
+	Timestamp TimeType = "timestamp"
+	// TimestampWithTimeZone stores the offset along with the time, the offset survives a round trip
+	TimestampWithTimeZone TimeType = "timestamp with time zone"
+	// TimestampWithLocalTimeZone stores the time normalized to the database time zone and reads it in the session
+	// time zone
+	TimestampWithLocalTimeZone TimeType = "timestamp with local time zone"
+)
+
+// timeTypeOf returns the type of a time field, the timezone tag overrides Config.TimeType:
+// `gorm:"timezone"` for TIMESTAMP WITH TIME ZONE, `gorm:"timezone:local"` for TIMESTAMP WITH LOCAL TIME ZONE and
+// `gorm:"timezone:none"` for TIMESTAMP
+func (d Dialector) timeTypeOf(field *schema.Field) string {
+	timeType := d.TimeType
+	if value, ok := field.TagSettings["TIMEZONE"]; ok {
+		switch strings.ToUpper(value) {
+		case "LOCAL":
+			timeType = TimestampWithLocalTimeZone
+		case "NONE", "FALSE":
+			timeType = Timestamp
+		default:
+			timeType = TimestampWithTimeZone
+		}
+	}
+	if timeType == "" {
+		timeType = DateTime
+	}
+
+	sqlType := string(timeType)
+	if field.Precision > 0 {
+		// the precision follows the type name: timestamp(6) with time zone
+		name, zone, _ := strings.Cut(sqlType, " ")
+		sqlType = fmt.Sprintf("%s(%d)", name, field.Precision)
+		if zone != "" {
+			sqlType += " " + zone
+		}
+	}
+
+	if field.NotNull || field.PrimaryKey {
+		return sqlType
+	}
+	return sqlType + " NULL"
+}
+
+// openConnector returns the connector of the registered driver for dsn, so the connections it makes can be wrapped
+func openConnector(driverName, dsn string) (driver.Connector, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+
+	if driverContext, ok := drv.(driver.DriverContext); ok {
+		return driverContext.OpenConnector(dsn)
+	}
+	return dsnConnector{dsn: dsn, driver: drv}, nil
+}
+
+// dsnConnector connects with a driver which has no connector of its own
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// ErrTimeZoneWithConn is returned when Config.TimeZone is set along with Config.Conn, whose connections are not
+// opened by the dialector
+var ErrTimeZoneWithConn = errors.New("Config.TimeZone needs Config.DSN, set the time zone of Config.Conn in its DSN")
+
+// sessionConnector sets the session time zone of every new connection. DM session time zones are offsets, the
+// offset of location is taken when the connection is made and is not followed across daylight saving changes
+type sessionConnector struct {
+	driver.Connector
+	location *time.Location
+}
+
+func (c sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SET TIME ZONE '%s'", timeZoneOffset(time.Now().In(c.location)))
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err = execer.ExecContext(ctx, query, nil)
+	} else {
+		var stmt driver.Stmt
+		if stmt, err = conn.Prepare(query); err == nil {
+			_, err = stmt.Exec(nil)
+			stmt.Close()
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// timeZoneOffset formats the offset of t as DM expects it, e.g. +08:00
+func timeZoneOffset(t time.Time) string {
+	_, offset := t.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,99 @@
+package gorm_dm8
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type Event struct {
+	ID        int
+	StartedAt time.Time
+	EndedAt   time.Time `gorm:"not null;precision:6"`
+	ZonedAt   time.Time `gorm:"timezone;precision:6"`
+	LocalAt   time.Time `gorm:"timezone:local;not null"`
+	PlainAt   time.Time `gorm:"timezone:none"`
+}
+
+func TestDataTypeOfTimes(t *testing.T) {
+	s, err := schema.Parse(&Event{}, &sync.Map{}, Namer{})
+	if err != nil {
+		t.Fatalf("failed to parse schema, got error %v", err)
+	}
+
+	for timeType, expected := range map[TimeType]map[string]string{
+		"": {
+			"StartedAt": "datetime NULL",
+			"EndedAt":   "datetime(6)",
+			"ZonedAt":   "timestamp(6) with time zone NULL",
+			"LocalAt":   "timestamp with local time zone",
+			"PlainAt":   "timestamp NULL",
+		},
+		TimestampWithTimeZone: {
+			"StartedAt": "timestamp with time zone NULL",
+			"EndedAt":   "timestamp(6) with time zone",
+			"PlainAt":   "timestamp NULL",
+		},
+	} {
+		dialector := Dialector{Config: &Config{TimeType: timeType}}
+		for name, dataType := range expected {
+			if result := dialector.DataTypeOf(s.LookUpField(name)); result != dataType {
+				t.Errorf("expected data type %v for %v with %q, got %v", dataType, name, timeType, result)
+			}
+		}
+	}
+}
+
+func TestTimeZoneOffset(t *testing.T) {
+	for offset, expected := range map[int]string{0: "+00:00", 8 * 3600: "+08:00", -(3*3600 + 1800): "-03:30"} {
+		if result := timeZoneOffset(time.Date(2023, 1, 1, 0, 0, 0, 0, time.FixedZone("", offset))); result != expected {
+			t.Errorf("expected offset %v, got %v", expected, result)
+		}
+	}
+}
+
+func TestCreateTableTimeTypes(t *testing.T) {
+	db, recorder := record(dryRun(t, Config{TimeType: TimestampWithTimeZone}))
+	if err := db.Migrator().CreateTable(&Event{}); err != nil {
+		t.Fatalf("failed to create table, got error %v", err)
+	}
+
+	for _, column := range []string{
+		`"STARTED_AT" TIMESTAMP WITH TIME ZONE NULL`,
+		`"ENDED_AT" TIMESTAMP(6) WITH TIME ZONE NOT NULL`,
+		`"ZONED_AT" TIMESTAMP(6) WITH TIME ZONE NULL`,
+		`"LOCAL_AT" TIMESTAMP WITH LOCAL TIME ZONE NOT NULL`,
+		`"PLAIN_AT" TIMESTAMP NULL`,
+	} {
+		if len(recorder.sqls) == 0 || !strings.Contains(recorder.sqls[0], column) {
+			t.Errorf("expected %v in %v", column, recorder.sqls)
+		}
+	}
+}
+
+func TestSessionTimeZone(t *testing.T) {
+	fake := &fakeDB{}
+	db := fake.openDriver(t, Config{TimeZone: time.FixedZone("", -(3*3600 + 1800))})
+	if err := db.Exec("DELETE FROM EVENTS").Error; err != nil {
+		t.Fatalf("failed to exec, got error %v", err)
+	}
+
+	expected := []string{"SET TIME ZONE '-03:30'", "DELETE FROM EVENTS"}
+	if statements := fake.statements(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected the session time zone to be set when connecting, got %v", statements)
+	}
+}
+
+func TestSessionTimeZoneConn(t *testing.T) {
+	_, err := gorm.Open(New(Config{Conn: &sql.DB{}, TimeZone: time.UTC}), &gorm.Config{DisableAutomaticPing: true})
+	if !errors.Is(err, ErrTimeZoneWithConn) {
+		t.Errorf("expected the time zone to be refused with Conn, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// UUIDType is the column type of UUID fields
+type UUIDType int
+
+const (
+	// UUIDAsString stores UUIDs as CHAR(36) in their canonical text form, generated with `default:NEWID()`
+	UUIDAsString UUIDType = iota
+	// UUIDAsBinary stores 16 byte UUIDs as BINARY(16), generated with `default:HEXTORAW(GUID())`. String fields
+	// tagged `type:uuid` stay CHAR(36)
+	UUIDAsBinary
+)
+
+// isUUID reports whether the field holds a UUID, i.e. it has a UUID type such as uuid.UUID or it is tagged type:uuid
+func isUUID(field *schema.Field) bool {
+	if strings.EqualFold(string(field.DataType), "uuid") {
+		return true
+	}
+	if _, ok := field.TagSettings["TYPE"]; ok {
+		return false
+	}
+	return isUUIDType(field.IndirectFieldType)
+}
+
+// valuerType is the type of driver.Valuer, which UUID types implement
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// isUUIDType reports whether typ is a UUID type like uuid.UUID, a 16 byte array handing itself out as driver.Valuer.
+// Other 16 byte arrays such as MD5 sums are no UUIDs
+func isUUIDType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8 &&
+		(typ.Implements(valuerType) || reflect.PtrTo(typ).Implements(valuerType))
+}
+
+// uuidTypeOf returns the type of a UUID field
+func (d Dialector) uuidTypeOf(field *schema.Field) string {
+	if d.UUIDType == UUIDAsBinary && isUUIDType(field.IndirectFieldType) {
+		return "binary(16)"
+	}
+	return "char(36)"
+}
+
+// convertUUID binds a value of a UUID type, e.g. uuid.UUID whose driver.Valuer always hands out text, in the format of
+// Config.UUIDType
+func (d Dialector) convertUUID(val interface{}) interface{} {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || !isUUIDType(rv.Type()) {
+		return val
+	}
+
+	b := make([]byte, 16)
+	reflect.Copy(reflect.ValueOf(b), rv)
+	if d.UUIDType == UUIDAsBinary {
+		return b
+	}
+	return formatUUID(b)
+}
+
+// formatUUID formats 16 bytes as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func formatUUID(b []byte) string {
+	s := hex.EncodeToString(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// parseUUID parses the text of a UUID with or without dashes, or returns 16 bytes as is
+func parseUUID(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 16 {
+			return v, nil
+		}
+		return parseUUID(string(v))
+	case string:
+		s := strings.Trim(strings.ReplaceAll(v, "-", ""), "{}")
+		if b, err := hex.DecodeString(s); err == nil && len(b) == 16 {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid UUID %v", value)
+}
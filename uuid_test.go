@@ -0,0 +1,78 @@
+package gorm_dm8
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// testUUID behaves like uuid.UUID, its driver.Valuer hands out text
+type testUUID [16]byte
+
+func (u testUUID) Value() (driver.Value, error) {
+	return formatUUID(u[:]), nil
+}
+
+type Ticket struct {
+	ID       testUUID `gorm:"primaryKey;default:NEWID()"`
+	Raw      [16]byte
+	Ref      string `gorm:"type:uuid"`
+	Checksum []byte `gorm:"size:16"`
+}
+
+func TestUUIDDataType(t *testing.T) {
+	s, err := schema.Parse(&Ticket{}, &sync.Map{}, Namer{})
+	if err != nil {
+		t.Fatalf("failed to parse schema, got error %v", err)
+	}
+	if len(s.FieldsWithDefaultDBValue) != 1 || s.FieldsWithDefaultDBValue[0].Name != "ID" {
+		t.Errorf("expected the generated UUID to be returned after create, got %v", s.FieldsWithDefaultDBValue)
+	}
+
+	if isUUID(s.LookUpField("Raw")) {
+		t.Errorf("expected a 16 byte array which is no UUID type not to be a UUID")
+	}
+
+	for uuidType, expected := range map[UUIDType]map[string]string{
+		UUIDAsString: {"ID": "char(36)", "Ref": "char(36)", "Checksum": "binary(16)"},
+		UUIDAsBinary: {"ID": "binary(16)", "Ref": "char(36)", "Checksum": "binary(16)"},
+	} {
+		dialector := Dialector{Config: &Config{UUIDType: uuidType}}
+		for name, dataType := range expected {
+			if result := dialector.DataTypeOf(s.LookUpField(name)); result != dataType {
+				t.Errorf("expected data type %v for %v, got %v", dataType, name, result)
+			}
+		}
+	}
+}
+
+func TestUUIDVars(t *testing.T) {
+	id, _ := parseUUID("550e8400-e29b-41d4-a716-446655440000")
+	var u testUUID
+	copy(u[:], id)
+
+	for uuidType, expected := range map[UUIDType]interface{}{
+		UUIDAsString: "550e8400-e29b-41d4-a716-446655440000",
+		UUIDAsBinary: id,
+	} {
+		dialector := Dialector{Config: &Config{UUIDType: uuidType}}
+		for _, v := range []interface{}{u, &u} {
+			if result := dialector.convertVar(v); !reflect.DeepEqual(result, expected) {
+				t.Errorf("expected %T bound as %v, got %v", v, expected, result)
+			}
+		}
+		if result := dialector.convertVar([16]byte(u)); result != [16]byte(u) {
+			t.Errorf("expected a 16 byte array which is no UUID to be left alone, got %v", result)
+		}
+	}
+
+	if b, err := parseUUID("{550E8400E29B41D4A716446655440000}"); err != nil || !reflect.DeepEqual(b, id) {
+		t.Errorf("failed to parse UUID, got %v, %v", b, err)
+	}
+	if _, err := parseUUID("550e8400"); err == nil {
+		t.Errorf("expected short UUID to be refused")
+	}
+}